@@ -89,3 +89,48 @@ func (et EasyTask) Status() TaskStatus {
 func (et EasyTask) Context() context.Context {
 	return et.TaskContext
 }
+
+// EasyWorker is an embeddable struct which gives a task access to the worker
+// executing it. The worker ID and the state returned by
+// [async.WorkerPoolOptions.OnWorkerStart] are set before [async.Task.Do] is called.
+// Tasks executed by the caller of AddTask with [async.OverflowPolicyCallerRuns] get
+// [async.CallerWorkerID] and no state.
+// Sample usage:
+//
+//	type InsertRowTask struct {
+//		Row Row
+//		async.EasyTask
+//		async.EasyWorker
+//	}
+//	func (t *InsertRowTask) Do() {
+//		conn, ok := t.WorkerState().(*sql.Conn) // state created by OnWorkerStart
+//		if !ok { // executed by the caller of AddTask
+//			// open a connection...
+//		}
+//		// insert row...
+//	}
+type EasyWorker struct {
+	workerID    int
+	workerState any
+}
+
+// WorkerID returns the ID of the worker executing the task, CallerWorkerID if
+// executed by the caller of AddTask
+func (ew *EasyWorker) WorkerID() int {
+	return ew.workerID
+}
+
+// WorkerState returns the state of the worker executing the task, nil if executed
+// by the caller of AddTask
+func (ew *EasyWorker) WorkerState() any {
+	return ew.workerState
+}
+
+func (ew *EasyWorker) setWorker(id int, state any) {
+	ew.workerID = id
+	ew.workerState = state
+}
+
+type easyWorker interface {
+	setWorker(id int, state any)
+}
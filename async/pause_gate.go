@@ -0,0 +1,50 @@
+package async
+
+import "sync"
+
+// pauseGate blocks workers while the worker pool is paused.
+type pauseGate struct {
+	mu      sync.Mutex
+	paused  bool
+	resumed chan struct{}
+}
+
+func (g *pauseGate) pause() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.paused {
+		return
+	}
+	g.paused = true
+	g.resumed = make(chan struct{})
+}
+
+func (g *pauseGate) resume() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if !g.paused {
+		return
+	}
+	g.paused = false
+	close(g.resumed)
+}
+
+func (g *pauseGate) isPaused() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.paused
+}
+
+// wait blocks until the gate is not paused.
+func (g *pauseGate) wait() {
+	g.mu.Lock()
+	if !g.paused {
+		g.mu.Unlock()
+		return
+	}
+	resumed := g.resumed
+	g.mu.Unlock()
+	<-resumed
+}
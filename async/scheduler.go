@@ -0,0 +1,111 @@
+package async
+
+// Scheduler indicates how queued tasks are distributed to the workers of a WorkerPool.
+type Scheduler int
+
+const (
+	// SchedulerChannel queues tasks in a single channel shared by all workers.
+	SchedulerChannel Scheduler = 0
+
+	// SchedulerWorkStealing queues tasks in a deque per worker. Workers with an
+	// empty deque steal tasks from the other workers. This reduces contention for
+	// many small CPU-bound tasks.
+	SchedulerWorkStealing Scheduler = 1
+)
+
+// scheduler holds the queued tasks of a worker pool.
+type scheduler interface {
+	// tryPush queues the task without blocking. Returns false if the queue is full.
+	tryPush(task Task) bool
+
+	// tryPushBatch queues the tasks without blocking until the queue is full.
+	// Returns the number of tasks queued.
+	tryPushBatch(tasks []Task) int
+
+	// push queues the task, blocking until the queue has room or done is closed.
+	// Returns false if done was closed first.
+	push(task Task, done <-chan struct{}) bool
+
+	// popOldest removes the oldest queued task without blocking.
+	popOldest() (Task, bool)
+
+	// next blocks until there is a task for the worker or quit is closed.
+	next(workerID int, quit <-chan struct{}) (Task, bool)
+
+	// tryNext returns a task for the worker without blocking.
+	tryNext(workerID int) (Task, bool)
+
+	// len returns the number of queued tasks.
+	len() int
+}
+
+func newScheduler(scheduler Scheduler, workers int, maxQueuedTask int) scheduler {
+	if scheduler == SchedulerWorkStealing {
+		return newStealingScheduler(workers, maxQueuedTask)
+	}
+	return newChannelScheduler(maxQueuedTask)
+}
+
+// channelScheduler queues tasks in a single channel shared by all workers.
+type channelScheduler struct {
+	taskQueue chan Task
+}
+
+func newChannelScheduler(maxQueuedTask int) *channelScheduler {
+	return &channelScheduler{
+		taskQueue: make(chan Task, maxQueuedTask),
+	}
+}
+
+func (s *channelScheduler) tryPush(task Task) bool {
+	select {
+	case s.taskQueue <- task:
+		return true
+	default:
+		return false
+	}
+}
+
+func (s *channelScheduler) tryPushBatch(tasks []Task) int {
+	for i, task := range tasks {
+		if !s.tryPush(task) {
+			return i
+		}
+	}
+	return len(tasks)
+}
+
+func (s *channelScheduler) push(task Task, done <-chan struct{}) bool {
+	select {
+	case s.taskQueue <- task:
+		return true
+	case <-done:
+		return false
+	}
+}
+
+func (s *channelScheduler) popOldest() (Task, bool) {
+	select {
+	case task := <-s.taskQueue:
+		return task, true
+	default:
+		return nil, false
+	}
+}
+
+func (s *channelScheduler) next(workerID int, quit <-chan struct{}) (Task, bool) {
+	select {
+	case task := <-s.taskQueue:
+		return task, true
+	case <-quit:
+		return nil, false
+	}
+}
+
+func (s *channelScheduler) tryNext(workerID int) (Task, bool) {
+	return s.popOldest()
+}
+
+func (s *channelScheduler) len() int {
+	return len(s.taskQueue)
+}
@@ -0,0 +1,252 @@
+package async
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// queuedTask is a task queued in a taskDeque with its sequence number, which
+// orders tasks across the deques.
+type queuedTask struct {
+	task Task
+	seq  uint64
+}
+
+// taskDeque is a double-ended queue of tasks owned by a single worker.
+type taskDeque struct {
+	mu    sync.Mutex
+	tasks []queuedTask
+}
+
+// pushBack numbers the tasks from seq while the deque is locked, so that the
+// tasks of every deque are in order.
+func (d *taskDeque) pushBack(seq *atomic.Uint64, tasks ...Task) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, task := range tasks {
+		d.tasks = append(d.tasks, queuedTask{task: task, seq: seq.Add(1)})
+	}
+}
+
+func (d *taskDeque) popFront() (Task, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if len(d.tasks) == 0 {
+		return nil, false
+	}
+	return d.removeFront(), true
+}
+
+// popFrontSeq removes the front task only if its sequence number is seq.
+func (d *taskDeque) popFrontSeq(seq uint64) (Task, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if len(d.tasks) == 0 || d.tasks[0].seq != seq {
+		return nil, false
+	}
+	return d.removeFront(), true
+}
+
+func (d *taskDeque) removeFront() Task {
+	task := d.tasks[0].task
+	d.tasks[0] = queuedTask{}
+	d.tasks = d.tasks[1:]
+	return task
+}
+
+// frontSeq returns the sequence number of the front task.
+func (d *taskDeque) frontSeq() (uint64, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if len(d.tasks) == 0 {
+		return 0, false
+	}
+	return d.tasks[0].seq, true
+}
+
+func (d *taskDeque) popBack() (Task, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if len(d.tasks) == 0 {
+		return nil, false
+	}
+	last := len(d.tasks) - 1
+	task := d.tasks[last].task
+	d.tasks[last] = queuedTask{}
+	d.tasks = d.tasks[:last]
+	return task, true
+}
+
+// stealingScheduler queues tasks in a deque per worker. Workers take tasks from
+// the front of their own deque and steal from the back of the others when it is empty.
+type stealingScheduler struct {
+	deques []*taskDeque
+
+	// queued is the number of queued tasks, limited by maxQueued.
+	queued    atomic.Int64
+	maxQueued int64
+
+	// room signals blocked pushers that a task has been taken.
+	room    chan struct{}
+	waiting atomic.Int32
+
+	// wakeup signals idle workers that tasks have been queued.
+	wakeup chan struct{}
+
+	// nextDeque is used to distribute new tasks to the deques in round-robin.
+	nextDeque atomic.Uint32
+
+	// seq numbers the queued tasks, see queuedTask.
+	seq atomic.Uint64
+}
+
+func newStealingScheduler(workers int, maxQueuedTask int) *stealingScheduler {
+	s := &stealingScheduler{
+		maxQueued: int64(maxQueuedTask),
+		room:      make(chan struct{}, 1),
+		wakeup:    make(chan struct{}, workers),
+	}
+	for i := 0; i < workers; i++ {
+		s.deques = append(s.deques, &taskDeque{})
+	}
+	return s
+}
+
+func (s *stealingScheduler) tryAcquireSlot() bool {
+	for {
+		queued := s.queued.Load()
+		if queued >= s.maxQueued {
+			return false
+		}
+		if s.queued.CompareAndSwap(queued, queued+1) {
+			return true
+		}
+	}
+}
+
+func (s *stealingScheduler) releaseSlot() {
+	s.queued.Add(-1)
+	if s.waiting.Load() > 0 {
+		s.signalRoom()
+	}
+}
+
+func (s *stealingScheduler) signalRoom() {
+	select {
+	case s.room <- struct{}{}:
+	default:
+	}
+}
+
+func (s *stealingScheduler) tryPush(task Task) bool {
+	if !s.tryAcquireSlot() {
+		return false
+	}
+	s.enqueue(task)
+	return true
+}
+
+func (s *stealingScheduler) tryPushBatch(tasks []Task) int {
+	acquired := 0
+	for acquired < len(tasks) && s.tryAcquireSlot() {
+		acquired++
+	}
+
+	// split the batch evenly so that every deque is locked once
+	chunk := (acquired + len(s.deques) - 1) / len(s.deques)
+	for start := 0; start < acquired; start += chunk {
+		end := min(start+chunk, acquired)
+		s.enqueue(tasks[start:end]...)
+	}
+	return acquired
+}
+
+func (s *stealingScheduler) push(task Task, done <-chan struct{}) bool {
+	s.waiting.Add(1)
+	defer s.waiting.Add(-1)
+
+	for !s.tryAcquireSlot() {
+		select {
+		case <-s.room:
+		case <-done:
+			return false
+		}
+	}
+	// pass the signal on to other blocked pushers if there is still room
+	if s.queued.Load() < s.maxQueued {
+		s.signalRoom()
+	}
+	s.enqueue(task)
+	return true
+}
+
+// enqueue adds the tasks to a deque. A slot must have been acquired for every task.
+func (s *stealingScheduler) enqueue(tasks ...Task) {
+	i := int(s.nextDeque.Add(1)) % len(s.deques)
+	s.deques[i].pushBack(&s.seq, tasks...)
+	for range tasks {
+		select {
+		case s.wakeup <- struct{}{}:
+		default:
+			return // enough workers have been signaled
+		}
+	}
+}
+
+// popOldest removes the task with the smallest sequence number among the fronts of
+// the deques, which workers only steal from the back.
+func (s *stealingScheduler) popOldest() (Task, bool) {
+	for {
+		oldest, oldestSeq := -1, uint64(0)
+		for i, deque := range s.deques {
+			if seq, ok := deque.frontSeq(); ok && (oldest < 0 || seq < oldestSeq) {
+				oldest, oldestSeq = i, seq
+			}
+		}
+		if oldest < 0 {
+			return nil, false
+		}
+		if task, ok := s.deques[oldest].popFrontSeq(oldestSeq); ok {
+			s.releaseSlot()
+			return task, true
+		}
+		// the task was taken by a worker meanwhile
+	}
+}
+
+func (s *stealingScheduler) next(workerID int, quit <-chan struct{}) (Task, bool) {
+	for {
+		if task, ok := s.tryNext(workerID); ok {
+			return task, true
+		}
+		select {
+		case <-s.wakeup:
+		case <-quit:
+			return nil, false
+		}
+	}
+}
+
+func (s *stealingScheduler) tryNext(workerID int) (Task, bool) {
+	own := workerID % len(s.deques)
+	if task, ok := s.deques[own].popFront(); ok {
+		s.releaseSlot()
+		return task, true
+	}
+	for i := 1; i < len(s.deques); i++ {
+		victim := s.deques[(own+i)%len(s.deques)]
+		if task, ok := victim.popBack(); ok {
+			s.releaseSlot()
+			return task, true
+		}
+	}
+	return nil, false
+}
+
+func (s *stealingScheduler) len() int {
+	return int(s.queued.Load())
+}
@@ -4,57 +4,89 @@ import (
 	"sync"
 )
 
-type workerStatus int
-
-const (
-	workerStatusPending = 0
-	workerStatusWorking = 1
-)
-
 type worker struct {
-	taskQueue chan Task
-	status    workerStatus
-	statusMu  sync.Mutex
+	id        int
+	scheduler scheduler
 	wg        *sync.WaitGroup
+	onStart   func(id int) (any, error)
+	onStop    func(id int, state any)
+	state     any
+	gate      *pauseGate
 }
 
-func newWorker(taskQueue chan Task, wg *sync.WaitGroup) *worker {
+func newWorker(id int, scheduler scheduler, wg *sync.WaitGroup, gate *pauseGate, options WorkerPoolOptions) *worker {
 	return &worker{
-		taskQueue: taskQueue,
-		status:    workerStatusPending,
+		id:        id,
+		scheduler: scheduler,
+		gate:      gate,
 		wg:        wg,
+		onStart:   options.OnWorkerStart,
+		onStop:    options.OnWorkerStop,
 	}
 }
 
-func (w *worker) start() {
-	w.statusMu.Lock()
-	defer w.statusMu.Unlock()
-
-	if w.status == workerStatusWorking {
-		return
+// setUp calls OnWorkerStart before the worker is started.
+func (w *worker) setUp() error {
+	if w.onStart != nil {
+		state, err := w.onStart(w.id)
+		if err != nil {
+			return err
+		}
+		w.state = state
 	}
-	w.status = workerStatusWorking
-	go w.doStart()
+	return nil
+}
 
+// tearDown calls OnWorkerStop after the worker stopped, or when it is not started
+// after setUp.
+func (w *worker) tearDown() {
+	if w.onStop != nil {
+		w.onStop(w.id, w.state)
+	}
+	w.state = nil
 }
 
-func (w *worker) doStart() {
+// start the worker after setUp. The worker executes tasks until quit is closed,
+// after which it executes the remaining queued tasks and stops.
+func (w *worker) start(quit <-chan struct{}) {
 	w.wg.Add(1)
-	for w.status == workerStatusWorking {
-		task := <-w.taskQueue
-		if task == nil {
-			continue
+	go w.doStart(quit)
+}
+
+func (w *worker) doStart(quit <-chan struct{}) {
+	defer w.wg.Done()
+	for {
+		w.gate.wait()
+		task, ok := w.scheduler.next(w.id, quit)
+		if !ok {
+			break
 		}
 
+		// the pool may have been paused while waiting for a task
+		w.gate.wait()
+
 		// TODO: check context deadline
-		doTask(task)
+		w.doTask(task)
+	}
 
+	w.drain()
+	w.tearDown()
+}
+
+// drain executes the queued tasks until the queue is empty.
+func (w *worker) drain() {
+	for {
+		task, ok := w.scheduler.tryNext(w.id)
+		if !ok {
+			return
+		}
+		w.doTask(task)
 	}
-	w.wg.Done()
 }
 
-func (w *worker) stop() {
-	w.statusMu.Lock()
-	defer w.statusMu.Unlock()
-	w.status = workerStatusPending
+func (w *worker) doTask(task Task) {
+	if workerTask, ok := task.(easyWorker); ok {
+		workerTask.setWorker(w.id, w.state)
+	}
+	doTask(task)
 }
@@ -3,12 +3,42 @@ package async
 import (
 	"fmt"
 	"sync"
-	"time"
 )
 
 const DefaultMaxQueuedTask = 20
 const DefaultWorkers = 1
 
+// ErrPoolStopped is returned by AddTask when the pool has been stopped.
+var ErrPoolStopped error = fmt.Errorf("pool is stopped")
+
+// ErrQueueFull is returned by AddTask when the queue is full and the overflow policy is OverflowPolicyReject.
+var ErrQueueFull error = fmt.Errorf("queue is full")
+
+// OverflowPolicy indicates what AddTask does when the queue of the worker pool is full.
+type OverflowPolicy int
+
+const (
+	// OverflowPolicyReject rejects the new task by returning ErrQueueFull.
+	OverflowPolicyReject OverflowPolicy = 0
+
+	// OverflowPolicyBlock blocks until the queue has room or the context of the task is done.
+	OverflowPolicyBlock OverflowPolicy = 1
+
+	// OverflowPolicyDropOldest drops the oldest queued task to make room for the new task.
+	OverflowPolicyDropOldest OverflowPolicy = 2
+
+	// OverflowPolicyDropNewest drops the new task.
+	OverflowPolicyDropNewest OverflowPolicy = 3
+
+	// OverflowPolicyCallerRuns executes the new task in the goroutine calling AddTask.
+	// Tasks embedding [async.EasyWorker] get CallerWorkerID and no worker state.
+	OverflowPolicyCallerRuns OverflowPolicy = 4
+)
+
+// CallerWorkerID is the worker ID of tasks executed by the goroutine calling AddTask,
+// see OverflowPolicyCallerRuns.
+const CallerWorkerID = -1
+
 // WorkerPoolOptions contains settings of WorkerPool
 type WorkerPoolOptions struct {
 	// Workers indicates the number of workers(routines) to be spawned
@@ -16,73 +46,280 @@ type WorkerPoolOptions struct {
 
 	// MaxQueuedTask indicates the maximum number of pending tasks to be executed by the worker pool.
 	MaxQueuedTask int
+
+	// OnWorkerStart is called when a worker starts, before it executes any task.
+	// The returned state is passed to tasks embedding [async.EasyWorker] and to
+	// OnWorkerStop. Returning an error makes Start fail without starting any worker.
+	OnWorkerStart func(id int) (state any, err error)
+
+	// OnWorkerStop is called when a worker stops, after it has executed its last task.
+	OnWorkerStop func(id int, state any)
+
+	// OverflowPolicy indicates what to do with a new task when the queue is full.
+	// Defaults to OverflowPolicyReject.
+	OverflowPolicy OverflowPolicy
+
+	// OnDrop is called with every task dropped by OverflowPolicyDropOldest or
+	// OverflowPolicyDropNewest. Dropped tasks are never executed.
+	OnDrop func(task Task)
+
+	// Scheduler indicates how queued tasks are distributed to the workers.
+	// Defaults to SchedulerChannel.
+	Scheduler Scheduler
 }
 
+type poolStatus int
+
+const (
+	poolStatusNew     poolStatus = 0
+	poolStatusRunning poolStatus = 1
+	poolStatusStopped poolStatus = 2
+)
+
 // WorkerPool maintains a group of workers which limits the number of routines that are spawned.
+// A pool can be started and stopped multiple times. Tasks added before the pool is
+// started are queued and executed once it starts.
 type WorkerPool struct {
-	mu        sync.Mutex
-	workers   []*worker
-	taskQueue chan Task
-	wg        sync.WaitGroup
+	mu             sync.Mutex
+	statusMu       sync.RWMutex
+	status         poolStatus
+	quit           chan struct{}
+	workers        []*worker
+	scheduler      scheduler
+	wg             sync.WaitGroup
+	adding         sync.WaitGroup // AddTask and AddTasks calls in progress
+	overflowPolicy OverflowPolicy
+	onDrop         func(task Task)
+	gate           pauseGate
+}
+
+// WorkerPoolStats is a snapshot of the state of a WorkerPool
+type WorkerPoolStats struct {
+	// Workers is the number of workers in the pool
+	Workers int
+
+	// QueuedTasks is the number of tasks waiting to be executed
+	QueuedTasks int
+
+	// Running indicates whether the pool has been started and not yet stopped
+	Running bool
+
+	// Paused indicates whether the pool is paused
+	Paused bool
 }
 
 // NewWorkerPool creates a new instance of WorkerPool
 func NewWorkerPool(options WorkerPoolOptions) *WorkerPool {
-	workerPool := WorkerPool{}
+	workerPool := WorkerPool{
+		overflowPolicy: options.OverflowPolicy,
+		onDrop:         options.OnDrop,
+	}
 
 	maxQueuedTask := DefaultMaxQueuedTask
 	if options.MaxQueuedTask > 0 {
 		maxQueuedTask = options.MaxQueuedTask
 	}
-	workerPool.taskQueue = make(chan Task, maxQueuedTask)
 
 	workers := DefaultWorkers
 	if options.Workers > 0 {
 		workers = options.Workers
 	}
+	workerPool.scheduler = newScheduler(options.Scheduler, workers, maxQueuedTask)
 	for i := 0; i < workers; i++ {
-		workerPool.workers = append(workerPool.workers, newWorker(workerPool.taskQueue, &workerPool.wg))
+		workerPool.workers = append(workerPool.workers, newWorker(i, workerPool.scheduler, &workerPool.wg, &workerPool.gate, options))
 	}
 
 	return &workerPool
 }
 
-// Start the workers
+// Start the workers. Starting a running pool does nothing. If
+// [async.WorkerPoolOptions.OnWorkerStart] returns an error, the error is returned,
+// [async.WorkerPoolOptions.OnWorkerStop] is called for the workers set up so far and
+// no worker is started, so that Start can be retried.
 func (wp *WorkerPool) Start() error {
 	wp.mu.Lock()
 	defer wp.mu.Unlock()
 
+	if wp.isRunning() {
+		return nil
+	}
+
+	for i, worker := range wp.workers {
+		err := worker.setUp()
+		if err != nil {
+			for _, setUp := range wp.workers[:i] {
+				setUp.tearDown()
+			}
+			return fmt.Errorf("failed to start worker %d: %w", worker.id, err)
+		}
+	}
+
+	wp.quit = make(chan struct{})
+	wp.setStatus(poolStatusRunning)
 	for _, worker := range wp.workers {
-		worker.start()
+		worker.start(wp.quit)
 	}
 	return nil
 }
 
-// Stop the workers
+// Stop the workers after all queued tasks are executed. A paused pool is resumed
+// so that the queued tasks can be executed. Stopping a pool that is not running does nothing.
+// Once stopped, AddTask returns ErrPoolStopped until the pool is started again.
 func (wp *WorkerPool) Stop() error {
 	wp.mu.Lock()
 	defer wp.mu.Unlock()
 
-	close(wp.taskQueue)
-
-	for len(wp.taskQueue) > 0 {
-		time.Sleep(500 * time.Millisecond)
+	if !wp.isRunning() {
+		return nil
 	}
 
-	for _, worker := range wp.workers {
-		worker.stop()
-	}
+	wp.gate.resume()
+
+	// waits for AddTask calls in progress, which may be blocked until workers make room
+	wp.setStatus(poolStatusStopped)
+	wp.adding.Wait()
 
+	close(wp.quit)
 	wp.wg.Wait()
 	return nil
 }
 
-// AddTask adds the task the pool of tasks
+func (wp *WorkerPool) setStatus(status poolStatus) {
+	wp.statusMu.Lock()
+	defer wp.statusMu.Unlock()
+	wp.status = status
+}
+
+func (wp *WorkerPool) isRunning() bool {
+	wp.statusMu.RLock()
+	defer wp.statusMu.RUnlock()
+	return wp.status == poolStatusRunning
+}
+
+// Pause the execution of queued tasks. Workers finish their current task, then idle
+// until Resume is called. Tasks can still be added while the pool is paused.
+func (wp *WorkerPool) Pause() {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+	wp.gate.pause()
+}
+
+// Resume the execution of queued tasks after Pause.
+func (wp *WorkerPool) Resume() {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+	wp.gate.resume()
+}
+
+// Stats returns a snapshot of the state of the pool
+func (wp *WorkerPool) Stats() WorkerPoolStats {
+	return WorkerPoolStats{
+		Workers:     len(wp.workers),
+		QueuedTasks: wp.scheduler.len(),
+		Running:     wp.isRunning(),
+		Paused:      wp.gate.isPaused(),
+	}
+}
+
+// AddTask adds the task the pool of tasks. ErrPoolStopped is returned if the pool
+// has been stopped. When the queue is full, the task is handled according to
+// [async.WorkerPoolOptions.OverflowPolicy].
 func (wp *WorkerPool) AddTask(task Task) error {
-	select {
-	case wp.taskQueue <- task:
+	err := wp.beginAdd()
+	if err != nil {
+		return err
+	}
+	defer wp.adding.Done()
+	return wp.addTask(task)
+}
+
+// AddTasks adds the tasks to the pool of tasks in a single batch, which is cheaper
+// than calling AddTask for every task. Tasks that do not fit in the queue are handled
+// according to [async.WorkerPoolOptions.OverflowPolicy]. If a task fails to be added,
+// the error is returned and the remaining tasks are not added.
+func (wp *WorkerPool) AddTasks(tasks []Task) error {
+	err := wp.beginAdd()
+	if err != nil {
+		return err
+	}
+	defer wp.adding.Done()
+
+	queued := wp.scheduler.tryPushBatch(tasks)
+	for i := queued; i < len(tasks); i++ {
+		err := wp.addTask(tasks[i])
+		if err != nil {
+			return fmt.Errorf("failed to add task %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// beginAdd registers an AddTask or AddTasks call, which Stop waits for. The status
+// lock is not held while adding, as a blocked add would otherwise block Start and
+// Stop. ErrPoolStopped is returned if the pool has been stopped.
+func (wp *WorkerPool) beginAdd() error {
+	wp.statusMu.RLock()
+	defer wp.statusMu.RUnlock()
+
+	if wp.status == poolStatusStopped {
+		return ErrPoolStopped
+	}
+	wp.adding.Add(1)
+	return nil
+}
+
+func (wp *WorkerPool) addTask(task Task) error {
+	if wp.scheduler.tryPush(task) {
+		return nil
+	}
+
+	switch wp.overflowPolicy {
+	case OverflowPolicyBlock:
+		return wp.addTaskBlocking(task)
+	case OverflowPolicyDropOldest:
+		wp.addTaskDropOldest(task)
+		return nil
+	case OverflowPolicyDropNewest:
+		wp.drop(task)
+		return nil
+	case OverflowPolicyCallerRuns:
+		if workerTask, ok := task.(easyWorker); ok {
+			workerTask.setWorker(CallerWorkerID, nil)
+		}
+		doTask(task)
 		return nil
 	default:
-		return fmt.Errorf("queue is full")
+		return ErrQueueFull
+	}
+}
+
+func (wp *WorkerPool) addTaskBlocking(task Task) error {
+	ctx := task.Context()
+	if ctx == nil {
+		wp.scheduler.push(task, nil)
+		return nil
+	}
+
+	if !wp.scheduler.push(task, ctx.Done()) {
+		return ctx.Err()
+	}
+	return nil
+}
+
+func (wp *WorkerPool) addTaskDropOldest(task Task) {
+	for !wp.scheduler.tryPush(task) {
+		if oldest, ok := wp.scheduler.popOldest(); ok {
+			wp.drop(oldest)
+		}
+	}
+}
+
+// drop reports the task to OnDrop and releases anyone waiting on it.
+func (wp *WorkerPool) drop(task Task) {
+	if wp.onDrop != nil {
+		wp.onDrop(task)
+	}
+	if easyWaiterTask, ok := task.(easyWaiter); ok {
+		easyWaiterTask.done()
 	}
 }
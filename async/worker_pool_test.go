@@ -1,7 +1,11 @@
 package async
 
 import (
+	"context"
 	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -33,7 +37,7 @@ func TestStop(t *testing.T) {
 	wp.AddTask(&testTask{doFunc: func() { t2Exec = true }})
 	wp.Stop()
 
-	assert.Equal(t, 0, len(wp.taskQueue), "taskQueue is not empty")
+	assert.Equal(t, 0, wp.Stats().QueuedTasks, "taskQueue is not empty")
 	assert.Equal(t, true, t1Exec, "task 1 not executed")
 	assert.Equal(t, true, t2Exec, "task 2 not executed")
 }
@@ -55,7 +59,7 @@ func TestStopFinishAllTasks(t *testing.T) {
 		t2Exec = true }})
 	wp.Stop()
 
-	assert.Equal(t, 0, len(wp.taskQueue), "taskQueue is not empty")
+	assert.Equal(t, 0, wp.Stats().QueuedTasks, "taskQueue is not empty")
 	assert.Equal(t, true, t1Exec, "task 1 not executed")
 	assert.Equal(t, true, t2Exec, "task 2 not executed")
 }
@@ -77,3 +81,425 @@ func TestStartEasyWait(t *testing.T) {
 
 	workerPool.Stop()
 }
+
+type workerStateTask struct {
+	EasyTask
+	EasyWorker
+	*EasyWait
+	gotID    int
+	gotState any
+}
+
+func (t *workerStateTask) Do() {
+	t.gotID = t.WorkerID()
+	t.gotState = t.WorkerState()
+}
+
+func TestWorkerHooks(t *testing.T) {
+	var mu sync.Mutex
+	started, stopped := map[int]any{}, map[int]any{}
+	opts := WorkerPoolOptions{
+		Workers: 3,
+		OnWorkerStart: func(id int) (any, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			state := fmt.Sprintf("state-%d", id)
+			started[id] = state
+			return state, nil
+		},
+		OnWorkerStop: func(id int, state any) {
+			mu.Lock()
+			defer mu.Unlock()
+			stopped[id] = state
+		},
+	}
+	wp := NewWorkerPool(opts)
+	assert.Nil(t, wp.Start(), "Start returned an error")
+
+	task := &workerStateTask{EasyWait: NewEasyWait()}
+	assert.Nil(t, wp.AddTask(task), "AddTask returned an error")
+	task.Wait()
+	wp.Stop()
+
+	assert.Equal(t, map[int]any{0: "state-0", 1: "state-1", 2: "state-2"}, started)
+	assert.Equal(t, started, stopped, "OnWorkerStop not called with worker state")
+	assert.Equal(t, fmt.Sprintf("state-%d", task.gotID), task.gotState, "Task did not receive worker state")
+}
+
+func TestWorkerHooksStartError(t *testing.T) {
+	startErr := fmt.Errorf("connection refused")
+	fail := true
+	var mu sync.Mutex
+	stopped := map[int]any{}
+	opts := WorkerPoolOptions{
+		Workers: 3,
+		OnWorkerStart: func(id int) (any, error) {
+			if id == 1 && fail {
+				return nil, startErr
+			}
+			return fmt.Sprintf("state-%d", id), nil
+		},
+		OnWorkerStop: func(id int, state any) {
+			mu.Lock()
+			defer mu.Unlock()
+			stopped[id] = state
+		},
+	}
+	wp := NewWorkerPool(opts)
+	executed := make(chan struct{})
+	assert.Nil(t, wp.AddTask(&testTask{doFunc: func() { close(executed) }}), "AddTask returned an error")
+
+	err := wp.Start()
+	assert.ErrorIs(t, err, startErr)
+	assert.Equal(t, map[int]any{0: "state-0"}, stopped, "Workers set up not stopped")
+	assert.False(t, wp.Stats().Running, "Pool running after failed start")
+	assert.Equal(t, 1, wp.Stats().QueuedTasks, "Task executed after failed start")
+
+	fail = false
+	assert.Nil(t, wp.Start(), "Start retry returned an error")
+	<-executed
+	wp.Stop()
+	assert.Equal(t, map[int]any{0: "state-0", 1: "state-1", 2: "state-2"}, stopped, "OnWorkerStop not called on stop")
+}
+
+func TestAddTaskOverflowPolicy(t *testing.T) {
+	tests := []struct {
+		name            string
+		policy          OverflowPolicy
+		err             error
+		expectedQueue   []int
+		expectedDropped []int
+		expectedRun     []int
+	}{
+		{
+			name:          "Reject",
+			policy:        OverflowPolicyReject,
+			err:           ErrQueueFull,
+			expectedQueue: []int{1, 2, 3},
+		},
+		{
+			name:            "Drop oldest",
+			policy:          OverflowPolicyDropOldest,
+			expectedQueue:   []int{3, 4, 5},
+			expectedDropped: []int{1, 2},
+		},
+		{
+			name:            "Drop newest",
+			policy:          OverflowPolicyDropNewest,
+			expectedQueue:   []int{1, 2, 3},
+			expectedDropped: []int{4, 5},
+		},
+		{
+			name:          "Caller runs",
+			policy:        OverflowPolicyCallerRuns,
+			expectedQueue: []int{1, 2, 3},
+			expectedRun:   []int{4, 5},
+		},
+	}
+
+	for _, scheduler := range []Scheduler{SchedulerChannel, SchedulerWorkStealing} {
+		for _, test := range tests {
+			t.Run(fmt.Sprintf("Scheduler %d/%s", scheduler, test.name), func(t *testing.T) {
+				var dropped, run []int
+				opts := WorkerPoolOptions{
+					Workers:        2,
+					MaxQueuedTask:  3,
+					OverflowPolicy: test.policy,
+					Scheduler:      scheduler,
+					OnDrop: func(task Task) {
+						dropped = append(dropped, task.Context().Value(taskIDKey{}).(int))
+					},
+				}
+				wp := NewWorkerPool(opts)
+
+				var err error
+				for id := 1; id <= 5; id++ {
+					id := id
+					task := &testTask{doFunc: func() { run = append(run, id) }}
+					task.TaskContext = context.WithValue(context.Background(), taskIDKey{}, id)
+					err = wp.AddTask(task)
+				}
+
+				assert.Equal(t, test.err, err, "Error not equal")
+				var queue []int
+				for task, ok := wp.scheduler.popOldest(); ok; task, ok = wp.scheduler.popOldest() {
+					queue = append(queue, task.Context().Value(taskIDKey{}).(int))
+				}
+				assert.Equal(t, test.expectedQueue, queue, "Queued tasks not equal")
+				assert.Equal(t, test.expectedDropped, dropped, "Dropped tasks not equal")
+				assert.Equal(t, test.expectedRun, run, "Tasks run by caller not equal")
+			})
+		}
+	}
+}
+
+func TestAddTaskOverflowPolicyCallerRunsWorker(t *testing.T) {
+	opts := WorkerPoolOptions{
+		MaxQueuedTask:  1,
+		OverflowPolicy: OverflowPolicyCallerRuns,
+		OnWorkerStart:  func(id int) (any, error) { return "state", nil },
+	}
+	wp := NewWorkerPool(opts)
+	assert.Nil(t, wp.AddTask(&testTask{}), "First task returned an error")
+
+	task := &workerStateTask{EasyWait: NewEasyWait(), gotID: 0, gotState: "unset"}
+	assert.Nil(t, wp.AddTask(task), "AddTask returned an error")
+	assert.Equal(t, CallerWorkerID, task.gotID, "Caller run task has a worker ID")
+	assert.Nil(t, task.gotState, "Caller run task has a worker state")
+}
+
+func TestAddTaskOverflowPolicyBlock(t *testing.T) {
+	opts := WorkerPoolOptions{
+		MaxQueuedTask:  1,
+		OverflowPolicy: OverflowPolicyBlock,
+	}
+	wp := NewWorkerPool(opts)
+	assert.Nil(t, wp.AddTask(&testTask{}), "First task returned an error")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	task := &testTask{}
+	task.TaskContext = ctx
+	err := wp.AddTask(task)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	wp.Start()
+	assert.Nil(t, wp.AddTask(&testTask{}), "Blocked task returned an error")
+	wp.Stop()
+}
+
+func TestAddTaskOverflowPolicyBlockBeforeStart(t *testing.T) {
+	opts := WorkerPoolOptions{
+		MaxQueuedTask:  1,
+		OverflowPolicy: OverflowPolicyBlock,
+	}
+	wp := NewWorkerPool(opts)
+	assert.Nil(t, wp.AddTask(&testTask{}), "First task returned an error")
+
+	executed := make(chan struct{})
+	added := make(chan error)
+	go func() {
+		added <- wp.AddTask(&testTask{doFunc: func() { close(executed) }})
+	}()
+	time.Sleep(50 * time.Millisecond) // lets AddTask block on the full queue
+
+	started := make(chan error)
+	go func() {
+		started <- wp.Start()
+	}()
+	select {
+	case err := <-started:
+		assert.Nil(t, err, "Start returned an error")
+	case <-time.After(time.Second):
+		t.Fatal("Start blocked by AddTask")
+	}
+	assert.Nil(t, <-added, "Blocked task returned an error")
+	<-executed
+	assert.True(t, wp.Stats().Running, "Pool not running")
+	wp.Stop()
+}
+
+type taskIDKey struct{}
+
+func TestPauseResume(t *testing.T) {
+	opts := WorkerPoolOptions{
+		Workers: 2,
+	}
+	wp := NewWorkerPool(opts)
+	wp.Start()
+	wp.Pause()
+
+	var mu sync.Mutex
+	executed := 0
+	tasks := []*easyWaiterTask{}
+	for i := 0; i < 3; i++ {
+		task := &easyWaiterTask{
+			EasyWait: NewEasyWait(),
+			doFunc: func() {
+				mu.Lock()
+				defer mu.Unlock()
+				executed++
+			},
+		}
+		tasks = append(tasks, task)
+		assert.Nil(t, wp.AddTask(task), "AddTask returned an error while paused")
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	stats := wp.Stats()
+	assert.True(t, stats.Paused, "Stats not paused")
+	assert.Equal(t, 2, stats.Workers, "Workers not equal")
+	mu.Lock()
+	assert.Equal(t, 0, executed, "Tasks executed while paused")
+	mu.Unlock()
+
+	wp.Resume()
+	for _, task := range tasks {
+		task.Wait()
+	}
+	assert.False(t, wp.Stats().Paused, "Stats still paused")
+	assert.Equal(t, 3, executed, "Tasks not executed after resume")
+	wp.Stop()
+}
+
+func TestStopWhilePaused(t *testing.T) {
+	wp := NewWorkerPool(WorkerPoolOptions{})
+	wp.Start()
+	wp.Pause()
+	executed := false
+	wp.AddTask(&testTask{doFunc: func() { executed = true }})
+	wp.Stop()
+
+	assert.True(t, executed, "Queued task not executed on stop")
+}
+
+func TestRestart(t *testing.T) {
+	startCount, stopCount := 0, 0
+	opts := WorkerPoolOptions{
+		Workers:       1,
+		OnWorkerStart: func(id int) (any, error) { startCount++; return nil, nil },
+		OnWorkerStop:  func(id int, state any) { stopCount++ },
+	}
+	wp := NewWorkerPool(opts)
+	for i := 0; i < 2; i++ {
+		assert.Nil(t, wp.Start(), "Start returned an error")
+		assert.True(t, wp.Stats().Running, "Pool not running after start")
+		executed := false
+		assert.Nil(t, wp.AddTask(&testTask{doFunc: func() { executed = true }}), "AddTask returned an error")
+		assert.Nil(t, wp.Stop(), "Stop returned an error")
+		assert.False(t, wp.Stats().Running, "Pool still running after stop")
+		assert.True(t, executed, "Task not executed")
+	}
+	assert.Equal(t, 2, startCount, "OnWorkerStart not called on every start")
+	assert.Equal(t, 2, stopCount, "OnWorkerStop not called on every stop")
+}
+
+func TestAddTaskStopped(t *testing.T) {
+	wp := NewWorkerPool(WorkerPoolOptions{})
+	wp.Start()
+	wp.Stop()
+
+	assert.Equal(t, ErrPoolStopped, wp.AddTask(&testTask{}))
+	assert.Nil(t, wp.Stop(), "Stopping a stopped pool returned an error")
+}
+
+func TestAddTaskBeforeStart(t *testing.T) {
+	wp := NewWorkerPool(WorkerPoolOptions{})
+	task := &easyWaiterTask{EasyWait: NewEasyWait()}
+	assert.Nil(t, wp.AddTask(task), "AddTask returned an error")
+	wp.Start()
+	task.Wait()
+	wp.Stop()
+}
+
+func TestWorkStealing(t *testing.T) {
+	opts := WorkerPoolOptions{
+		Workers:        4,
+		MaxQueuedTask:  64,
+		Scheduler:      SchedulerWorkStealing,
+		OverflowPolicy: OverflowPolicyBlock,
+	}
+	wp := NewWorkerPool(opts)
+	wp.Start()
+
+	var executed atomic.Int64
+	tasks := make([]Task, 1000)
+	for i := range tasks {
+		tasks[i] = &testTask{doFunc: func() { executed.Add(1) }}
+	}
+	assert.Nil(t, wp.AddTasks(tasks[:500]), "AddTasks returned an error")
+	for _, task := range tasks[500:] {
+		assert.Nil(t, wp.AddTask(task), "AddTask returned an error")
+	}
+	wp.Stop()
+
+	assert.Equal(t, int64(1000), executed.Load(), "Not all tasks executed")
+	assert.Equal(t, 0, wp.Stats().QueuedTasks, "Queue is not empty")
+}
+
+func TestWorkStealingStealsFromOtherWorkers(t *testing.T) {
+	s := newStealingScheduler(2, 10)
+	first, second := &testTask{}, &testTask{}
+	s.queued.Store(2)
+	s.deques[0].pushBack(&s.seq, first, second)
+
+	task, ok := s.tryNext(1)
+	assert.True(t, ok, "Task not stolen")
+	assert.Same(t, second, task, "Thief did not steal from the back")
+	task, ok = s.tryNext(0)
+	assert.True(t, ok, "Owner did not get task")
+	assert.Same(t, first, task, "Owner did not take from the front")
+	assert.Equal(t, 0, s.len(), "Queue is not empty")
+}
+
+func TestAddTasksFull(t *testing.T) {
+	for _, scheduler := range []Scheduler{SchedulerChannel, SchedulerWorkStealing} {
+		opts := WorkerPoolOptions{
+			Workers:       2,
+			MaxQueuedTask: 2,
+			Scheduler:     scheduler,
+		}
+		wp := NewWorkerPool(opts)
+		err := wp.AddTasks([]Task{&testTask{}, &testTask{}, &testTask{}})
+		assert.ErrorIs(t, err, ErrQueueFull)
+		assert.Equal(t, 2, wp.Stats().QueuedTasks, "Tasks that fit not queued")
+	}
+}
+
+type cpuTask struct {
+	EasyTask
+	sum *atomic.Int64
+}
+
+func (t *cpuTask) Do() {
+	n := int64(0)
+	for i := int64(0); i < 100; i++ {
+		n += i * i
+	}
+	t.sum.Add(n)
+}
+
+func BenchmarkWorkerPool(b *testing.B) {
+	schedulers := []struct {
+		name      string
+		scheduler Scheduler
+	}{
+		{name: "Channel", scheduler: SchedulerChannel},
+		{name: "WorkStealing", scheduler: SchedulerWorkStealing},
+	}
+	for _, s := range schedulers {
+		for _, batch := range []bool{false, true} {
+			name := s.name + "/AddTask"
+			if batch {
+				name = s.name + "/AddTasks"
+			}
+			b.Run(name, func(b *testing.B) {
+				opts := WorkerPoolOptions{
+					Workers:        runtime.GOMAXPROCS(0),
+					MaxQueuedTask:  1024,
+					Scheduler:      s.scheduler,
+					OverflowPolicy: OverflowPolicyBlock,
+				}
+				wp := NewWorkerPool(opts)
+				var sum atomic.Int64
+				tasks := make([]Task, b.N)
+				for i := range tasks {
+					tasks[i] = &cpuTask{sum: &sum}
+				}
+
+				b.ResetTimer()
+				wp.Start()
+				if batch {
+					for start := 0; start < len(tasks); start += 256 {
+						wp.AddTasks(tasks[start:min(start+256, len(tasks))])
+					}
+				} else {
+					for _, task := range tasks {
+						wp.AddTask(task)
+					}
+				}
+				wp.Stop()
+			})
+		}
+	}
+}
@@ -0,0 +1,261 @@
+// Command goeasy-mapgen writes reflection-free mapping functions for type pairs
+// of a package with obj.Generate. It is meant to be run by go generate from the
+// directory of the package:
+//
+//	//go:generate go run github.com/bryan-t/goeasy/cmd/goeasy-mapgen -pairs UserEntity:UserDTO -mapper NewMapper
+//
+// Flags:
+//
+//	-pairs   comma separated Source:Destination[:FuncName] type pairs of the package
+//	-mapper  name of a function of the package returning the configured *obj.Mapper,
+//	         obj.NewMapper() is used if empty
+//	-o       output file, mappers_gen.go by default
+//	-test    test file verifying the generated functions against the Mapper with
+//	         obj.VerifyGenerated, none if empty
+//
+// The configuration of the Mapper, e.g. obj.ConfigureFieldMaps, and struct tags are
+// honored as the package is loaded by running a temporary program in its directory.
+// The package must therefore compile and can't be a main package. The output is
+// replaced by functions calling Map while the package is loaded, so that outdated
+// generated code, e.g. reading a renamed field, does not prevent regenerating it.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"text/template"
+
+	"github.com/bryan-t/goeasy/obj"
+)
+
+// pair is a type pair of the package to generate a mapping function for
+type pair struct {
+	Source      string
+	Destination string
+	FuncName    string
+}
+
+// params are the parameters of the templates
+type params struct {
+	ObjPath     string
+	PackageName string
+	PackagePath string
+	Mapper      string
+	Output      string
+	Pairs       []pair
+}
+
+var programTemplate = template.Must(template.New("program").Parse(`package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"reflect"
+
+	"{{.ObjPath}}"
+	pkg "{{.PackagePath}}"
+)
+
+func main() {
+	mapper := {{if .Mapper}}pkg.{{.Mapper}}(){{else}}obj.NewMapper(){{end}}
+	var generated bytes.Buffer
+	err := obj.Generate(&generated, mapper, obj.GenerateConfig{
+		PackageName: {{printf "%q" .PackageName}},
+		PackagePath: {{printf "%q" .PackagePath}},
+		Pairs: []obj.GeneratePair{
+{{- range .Pairs}}
+			{
+				Source:      reflect.TypeFor[pkg.{{.Source}}](),
+				Destination: reflect.TypeFor[pkg.{{.Destination}}](),
+				FuncName:    {{printf "%q" .FuncName}},
+			},
+{{- end}}
+		},
+	})
+	if err == nil {
+		err = os.WriteFile({{printf "%q" .Output}}, generated.Bytes(), 0o644)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+`))
+
+var testTemplate = template.Must(template.New("test").Parse(`// Code generated by goeasy-mapgen. DO NOT EDIT.
+
+package {{.PackageName}}
+
+import (
+	"testing"
+
+	"{{.ObjPath}}"
+)
+
+func TestGeneratedMappers(t *testing.T) {
+	mapper := {{if .Mapper}}{{.Mapper}}(){{else}}obj.NewMapper(){{end}}
+{{- range .Pairs}}
+	if err := obj.VerifyGenerated(mapper, {{.FuncName}}); err != nil {
+		t.Error(err)
+	}
+{{- end}}
+}
+`))
+
+// stubTemplate replaces the output while the package is loaded, so that the package
+// compiles even if the previously generated code does not, e.g. after a field is renamed.
+var stubTemplate = template.Must(template.New("stub").Parse(`// Code generated by goeasy-mapgen. DO NOT EDIT.
+
+package {{.PackageName}}
+
+import (
+	"{{.ObjPath}}"
+)
+{{range .Pairs}}
+func {{.FuncName}}(mapper *obj.Mapper, src *{{.Source}}, dst *{{.Destination}}) error {
+	return mapper.Map(src, dst)
+}
+{{end}}`))
+
+func main() {
+	pairs := flag.String("pairs", "", "comma separated Source:Destination[:FuncName] type pairs")
+	mapper := flag.String("mapper", "", "name of a function of the package returning the *obj.Mapper")
+	output := flag.String("o", "mappers_gen.go", "output file")
+	testOutput := flag.String("test", "", "test file verifying the generated functions")
+	flag.Parse()
+
+	err := run(*pairs, *mapper, *output, *testOutput)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "goeasy-mapgen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(pairs string, mapper string, output string, testOutput string) error {
+	p := params{
+		ObjPath: reflect.TypeFor[obj.Mapper]().PkgPath(),
+		Mapper:  mapper,
+	}
+	var err error
+	p.Pairs, err = parsePairs(pairs)
+	if err != nil {
+		return err
+	}
+	p.Output, err = filepath.Abs(output)
+	if err != nil {
+		return err
+	}
+
+	list, err := exec.Command("go", "list", "-f", "{{.Name}} {{.ImportPath}}", ".").Output()
+	if err != nil {
+		return fmt.Errorf("failed to load package: %w", err)
+	}
+	p.PackageName, p.PackagePath, _ = strings.Cut(strings.TrimSpace(string(list)), " ")
+	if p.PackageName == "main" {
+		return fmt.Errorf("main packages are not supported")
+	}
+
+	err = generate(p)
+	if err != nil {
+		return err
+	}
+	if testOutput == "" {
+		return nil
+	}
+	test, err := render(testTemplate, p)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(testOutput, test, 0o644)
+}
+
+// generate runs a temporary program in the package directory which writes the
+// mapping functions with obj.Generate. The output is replaced by a stub while the
+// program runs, and restored if it fails.
+func generate(p params) (err error) {
+	program, err := render(programTemplate, p)
+	if err != nil {
+		return err
+	}
+
+	stub, err := render(stubTemplate, p)
+	if err != nil {
+		return err
+	}
+	previous, readErr := os.ReadFile(p.Output)
+	err = os.WriteFile(p.Output, stub, 0o644)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		switch {
+		case err == nil:
+		case readErr == nil:
+			os.WriteFile(p.Output, previous, 0o644)
+		default:
+			os.Remove(p.Output)
+		}
+	}()
+
+	dir, err := os.MkdirTemp(".", "goeasy-mapgen-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	err = os.WriteFile(filepath.Join(dir, "main.go"), program, 0o644)
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.Command("go", "run", "./"+filepath.Base(dir))
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	err = cmd.Run()
+	if err != nil {
+		return fmt.Errorf("failed to generate %s: %w", p.Output, err)
+	}
+	return nil
+}
+
+func render(tmpl *template.Template, p params) ([]byte, error) {
+	var src bytes.Buffer
+	err := tmpl.Execute(&src, p)
+	if err != nil {
+		return nil, err
+	}
+	return format.Source(src.Bytes())
+}
+
+// parsePairs parses comma separated Source:Destination[:FuncName] type pairs.
+func parsePairs(s string) ([]pair, error) {
+	if s == "" {
+		return nil, fmt.Errorf("pairs must be provided")
+	}
+
+	var pairs []pair
+	for _, value := range strings.Split(s, ",") {
+		parts := strings.Split(strings.TrimSpace(value), ":")
+		if len(parts) < 2 || len(parts) > 3 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("invalid pair %q, expected Source:Destination[:FuncName]", value)
+		}
+
+		p := pair{
+			Source:      parts[0],
+			Destination: parts[1],
+			FuncName:    "Map" + parts[0] + "To" + parts[1],
+		}
+		if len(parts) == 3 && parts[2] != "" {
+			p.FuncName = parts[2]
+		}
+		pairs = append(pairs, p)
+	}
+	return pairs, nil
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"go/parser"
+	"go/token"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePairs(t *testing.T) {
+	pairs, err := parsePairs("UserEntity:UserDTO, OrderEntity:OrderDTO:mapOrder")
+	assert.Nil(t, err, "parsePairs returned an error")
+	assert.Equal(t, []pair{
+		{Source: "UserEntity", Destination: "UserDTO", FuncName: "MapUserEntityToUserDTO"},
+		{Source: "OrderEntity", Destination: "OrderDTO", FuncName: "mapOrder"},
+	}, pairs)
+
+	_, err = parsePairs("")
+	assert.EqualError(t, err, "pairs must be provided")
+
+	_, err = parsePairs("UserEntity")
+	assert.EqualError(t, err, `invalid pair "UserEntity", expected Source:Destination[:FuncName]`)
+}
+
+func TestRender(t *testing.T) {
+	p := params{
+		ObjPath:     "github.com/bryan-t/goeasy/obj",
+		PackageName: "dto",
+		PackagePath: "example.com/dto",
+		Mapper:      "NewMapper",
+		Output:      "/tmp/mappers_gen.go",
+		Pairs:       []pair{{Source: "UserEntity", Destination: "UserDTO", FuncName: "MapUserEntityToUserDTO"}},
+	}
+
+	program, err := render(programTemplate, p)
+	assert.Nil(t, err, "render returned an error")
+	_, err = parser.ParseFile(token.NewFileSet(), "main.go", program, 0)
+	assert.Nil(t, err, "program does not parse")
+	assert.Contains(t, string(program), "reflect.TypeFor[pkg.UserEntity]()")
+	assert.Contains(t, string(program), "mapper := pkg.NewMapper()")
+
+	test, err := render(testTemplate, p)
+	assert.Nil(t, err, "render returned an error")
+	assert.Contains(t, string(test), "package dto")
+	assert.Contains(t, string(test), "obj.VerifyGenerated(mapper, MapUserEntityToUserDTO)")
+}
+
+func TestRunRegenerate(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs the go command")
+	}
+	wd, err := os.Getwd()
+	assert.Nil(t, err, "Getwd returned an error")
+	dir, err := os.MkdirTemp(".", "_e2e")
+	assert.Nil(t, err, "MkdirTemp returned an error")
+	defer os.RemoveAll(filepath.Join(wd, dir))
+	assert.Nil(t, os.Chdir(dir), "Chdir returned an error")
+	defer os.Chdir(wd)
+
+	writeFile := func(name string, src string) {
+		assert.Nil(t, os.WriteFile(name, []byte(src), 0o644), "WriteFile returned an error")
+	}
+	writeFile("user.go", `package e2e
+
+type UserEntity struct{ Name string }
+
+type UserDTO struct{ Name string }
+
+func toDTO(user UserEntity) (dto UserDTO, err error) {
+	err = MapUserEntityToUserDTO(nil, &user, &dto)
+	return dto, err
+}
+`)
+	err = run("UserEntity:UserDTO", "", "mappers_gen.go", "mappers_gen_test.go")
+	assert.Nil(t, err, "run returned an error")
+	generated, _ := os.ReadFile("mappers_gen.go")
+	assert.Contains(t, string(generated), "dst.Name = src.Name")
+
+	// the generated code no longer compiles once the source field is renamed
+	writeFile("user.go", `package e2e
+
+type UserEntity struct{ DisplayName string }
+
+type UserDTO struct{ DisplayName string }
+
+func toDTO(user UserEntity) (dto UserDTO, err error) {
+	err = MapUserEntityToUserDTO(nil, &user, &dto)
+	return dto, err
+}
+`)
+	err = run("UserEntity:UserDTO", "", "mappers_gen.go", "mappers_gen_test.go")
+	assert.Nil(t, err, "run returned an error after renaming a field")
+	generated, _ = os.ReadFile("mappers_gen.go")
+	assert.Contains(t, string(generated), "dst.DisplayName = src.DisplayName")
+
+	err = run("UserEntity:MissingDTO", "", "mappers_gen.go", "")
+	assert.NotNil(t, err, "run did not return an error for a missing type")
+	restored, _ := os.ReadFile("mappers_gen.go")
+	assert.Equal(t, string(generated), string(restored), "output not restored after failure")
+
+	out, err := exec.Command("go", "vet", ".").CombinedOutput()
+	assert.Nil(t, err, "generated package does not compile: %s", out)
+}
@@ -0,0 +1,106 @@
+package obj
+
+import (
+	"reflect"
+	"sync"
+)
+
+// cloneMethodName is the name of the method types can implement to clone themselves,
+// e.g. func (u *User) Clone() *User.
+const cloneMethodName = "Clone"
+
+var cloneMapper = NewMapper(WithPreserveReferences(), func(cfg *MapperConfig) {
+	cfg.clone = true
+})
+
+// cloneMethods caches the index of the Clone method of a type, -1 if none
+var cloneMethods sync.Map // reflect.Type -> int
+
+// Clone returns a deep copy of v: slices, maps, arrays, pointers and values in
+// interfaces are copied rather than shared, and pointers to the same value are
+// copied to pointers to the same copy, so cyclic values can be cloned. Channels
+// and functions are shared.
+//
+// Values of types with a Clone method taking no arguments and returning the same
+// type, e.g. func (u *User) Clone() *User, are cloned by calling it, except v
+// itself so that Clone can be used to implement the method. Unexported fields
+// can't be copied through reflection, so they are copied shallowly unless their
+// struct implements Clone.
+// Sample usage:
+//
+//	copied, err := obj.Clone(user)
+func Clone[T any](v T) (T, error) {
+	var dst T
+	state := mapState{path: make([]pathSegment, 0, pathCapacity)}
+	err := cloneMapper.mapValue(&state, reflect.ValueOf(&v).Elem(), reflect.ValueOf(&dst).Elem())
+	return dst, err
+}
+
+// cloneValue clones src to dst of the same type in the cases Map doesn't copy
+// deeply. Returns false if src is to be mapped as usual.
+func (m *Mapper) cloneValue(state *mapState, src reflect.Value, dst reflect.Value) (bool, error) {
+	if method := cloneMethod(src.Type()); method >= 0 && len(state.path) > 0 && src.CanInterface() {
+		if (src.Kind() == reflect.Pointer || src.Kind() == reflect.Interface) && src.IsNil() {
+			return true, nil
+		}
+		dst.Set(src.Method(method).Call(nil)[0])
+		return true, nil
+	}
+
+	switch src.Kind() {
+	case reflect.Chan, reflect.Func, reflect.Uintptr, reflect.UnsafePointer:
+		if src.CanInterface() {
+			dst.Set(src)
+		}
+		return true, nil
+	case reflect.Interface:
+		if src.IsNil() {
+			return true, nil
+		}
+		value := reflect.New(src.Elem().Type()).Elem()
+		err := m.mapValue(state, src.Elem(), value)
+		if err != nil {
+			return true, err
+		}
+		dst.Set(value)
+		return true, nil
+	case reflect.Map:
+		return src.IsNil(), nil
+	case reflect.Slice:
+		if !src.IsNil() {
+			dst.Set(reflect.MakeSlice(dst.Type(), 0, src.Len()))
+		}
+	case reflect.Struct:
+		if hasUnexportedFields(src.Type()) && src.CanInterface() {
+			dst.Set(src)
+			for i := 0; i < dst.NumField(); i++ {
+				if dst.Type().Field(i).IsExported() {
+					dst.Field(i).SetZero()
+				}
+			}
+		}
+	}
+	return false, nil
+}
+
+func cloneMethod(t reflect.Type) int {
+	if index, ok := cloneMethods.Load(t); ok {
+		return index.(int)
+	}
+	index := -1
+	method, ok := t.MethodByName(cloneMethodName)
+	if ok && method.Type.NumIn() == 1 && method.Type.NumOut() == 1 && method.Type.Out(0) == t {
+		index = method.Index
+	}
+	cloneMethods.Store(t, index)
+	return index
+}
+
+func hasUnexportedFields(t reflect.Type) bool {
+	for i := 0; i < t.NumField(); i++ {
+		if !t.Field(i).IsExported() {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,182 @@
+package obj
+
+import "reflect"
+
+// CollectionStrategy defines how a source slice or map is mapped to a destination
+// slice or map which already has elements.
+type CollectionStrategy int
+
+const (
+	// CollectionDefault uses the collection strategy of the Mapper, or
+	// CollectionAppend if none.
+	CollectionDefault CollectionStrategy = iota
+
+	// CollectionReplace replaces the destination with a new slice or map.
+	CollectionReplace
+
+	// CollectionAppend appends the source elements to the destination slice. Source
+	// entries are added to the destination map, replacing the values of existing keys.
+	CollectionAppend
+
+	// CollectionMergeByIndex maps each source element onto the destination element
+	// with the same index, appending the remaining source elements. The destination
+	// slice is truncated to the length of the source. Source entries are mapped onto
+	// the values of existing keys of the destination map.
+	CollectionMergeByIndex
+
+	// CollectionMergeByKey maps each source element onto the destination element
+	// with the same value of the key field, e.g. ID, for slices of structs, maps
+	// with string keys or pointers to them. Source elements without a match are
+	// appended and destination elements without a match are removed. Maps are
+	// merged as with CollectionMergeByIndex.
+	CollectionMergeByKey
+)
+
+// defaultCollectionKey is the key field of CollectionMergeByKey if none is configured.
+const defaultCollectionKey = "ID"
+
+// collectionStrategy returns the collection strategy and key field of a field,
+// fieldMap being nil if the field is not configured.
+func (m *Mapper) collectionStrategy(fieldMap *FieldMapConfig) (CollectionStrategy, string) {
+	strategy, key := m.cfg.collectionStrategy, m.cfg.collectionKey
+	if fieldMap != nil && fieldMap.CollectionStrategy != CollectionDefault {
+		strategy = fieldMap.CollectionStrategy
+	}
+	if fieldMap != nil && fieldMap.CollectionKey != "" {
+		key = fieldMap.CollectionKey
+	}
+	if strategy == CollectionDefault {
+		strategy = CollectionAppend
+	}
+	if key == "" {
+		key = defaultCollectionKey
+	}
+	return strategy, key
+}
+
+// mapSlice maps the elements of the slice or array src to the slice dst.
+func (m *Mapper) mapSlice(state *mapState, src reflect.Value, dst reflect.Value) error {
+	strategy, key := m.collectionStrategy(state.fieldMap())
+	switch strategy {
+	case CollectionReplace:
+		if src.Kind() == reflect.Slice && src.IsNil() {
+			dst.SetZero()
+			return nil
+		}
+		dst.Set(reflect.MakeSlice(dst.Type(), 0, src.Len()))
+	case CollectionMergeByIndex:
+		if dst.Len() > src.Len() {
+			dst.Set(dst.Slice(0, src.Len()))
+		}
+		for i := 0; i < dst.Len(); i++ {
+			if !isMergeable(dst.Type().Elem()) {
+				dst.Index(i).SetZero()
+			}
+			state.pushIndex(i)
+			err := m.mapValue(state, src.Index(i), dst.Index(i))
+			state.pop()
+			if err != nil {
+				return err
+			}
+		}
+		return m.appendSlice(state, src, dst, dst.Len())
+	case CollectionMergeByKey:
+		return m.mergeSliceByKey(state, src, dst, key)
+	}
+	return m.appendSlice(state, src, dst, 0)
+}
+
+// appendSlice appends the elements of src from index start to dst.
+func (m *Mapper) appendSlice(state *mapState, src reflect.Value, dst reflect.Value, start int) error {
+	for i := start; i < src.Len(); i++ {
+		dstElem := reflect.New(dst.Type().Elem())
+
+		state.pushIndex(i)
+		err := m.mapValue(state, src.Index(i), dstElem.Elem())
+		state.pop()
+		if err != nil {
+			return err
+		}
+		dst.Set(reflect.Append(dst, dstElem.Elem()))
+	}
+	return nil
+}
+
+// mergeSliceByKey maps each element of src onto the element of dst with the same key.
+func (m *Mapper) mergeSliceByKey(state *mapState, src reflect.Value, dst reflect.Value, key string) error {
+	indexes := make(map[any]int, dst.Len())
+	for i := 0; i < dst.Len(); i++ {
+		if dstKey, ok := elementKey(dst.Index(i), key); ok {
+			indexes[dstKey.Interface()] = i
+		}
+	}
+
+	merged := reflect.MakeSlice(dst.Type(), 0, src.Len())
+	for i := 0; i < src.Len(); i++ {
+		dstElem := reflect.New(dst.Type().Elem()).Elem()
+		if srcKey, ok := elementKey(src.Index(i), key); ok {
+			if j, ok := indexes[srcKey.Interface()]; ok && isMergeable(dstElem.Type()) {
+				dstElem.Set(dst.Index(j))
+				delete(indexes, srcKey.Interface()) // duplicate keys are appended
+			}
+		}
+
+		state.pushIndex(i)
+		err := m.mapValue(state, src.Index(i), dstElem)
+		state.pop()
+		if err != nil {
+			return err
+		}
+		merged = reflect.Append(merged, dstElem)
+	}
+	dst.Set(merged)
+	return nil
+}
+
+// isMergeable returns false for elements which can't be mapped onto, values in
+// interfaces, and are replaced instead.
+func isMergeable(elemType reflect.Type) bool {
+	return elemType.Kind() != reflect.Interface
+}
+
+// elementKey returns the value of the key field of a struct or map with string
+// keys, or of the value it points to. Returns false if there is no comparable key.
+// Keys are normalized so that keys of different integer, float or string types match.
+func elementKey(elem reflect.Value, key string) (reflect.Value, bool) {
+	for elem.Kind() == reflect.Pointer || elem.Kind() == reflect.Interface {
+		if elem.IsNil() {
+			return reflect.Value{}, false
+		}
+		elem = elem.Elem()
+	}
+
+	var value reflect.Value
+	switch {
+	case elem.Kind() == reflect.Struct:
+		field, ok := elem.Type().FieldByName(key)
+		if !ok || !field.IsExported() {
+			return reflect.Value{}, false
+		}
+		value, _ = elem.FieldByIndexErr(field.Index)
+	case isStringKeyedMap(elem.Type()):
+		value = elem.MapIndex(reflect.ValueOf(key).Convert(elem.Type().Key()))
+		if value.IsValid() && value.Kind() == reflect.Interface {
+			value = value.Elem()
+		}
+	}
+	if !value.IsValid() || !value.Comparable() || !value.CanInterface() {
+		return reflect.Value{}, false
+	}
+
+	switch {
+	case isIntKind(value.Kind()):
+		return reflect.ValueOf(value.Int()), true
+	case isUintKind(value.Kind()):
+		return reflect.ValueOf(value.Uint()), true
+	case isFloatKind(value.Kind()):
+		return reflect.ValueOf(value.Float()), true
+	case value.Kind() == reflect.String:
+		return reflect.ValueOf(value.String()), true
+	}
+	return value, true
+}
@@ -0,0 +1,264 @@
+package obj
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+)
+
+// Conversion indicates which conversions between kinds are allowed by Mapper.
+// Conversions can be combined, e.g. ConversionNarrowing | ConversionString.
+type Conversion int
+
+const (
+	// ConversionWidening allows lossless conversions between numeric kinds, e.g.
+	// int32 to int64, uint8 to int16, int16 to float32 and float32 to float64.
+	ConversionWidening Conversion = 1 << iota
+
+	// ConversionNarrowing allows conversions between numeric kinds which may not fit
+	// the destination, e.g. int64 to int8 or float64 to int. An *OverflowError is
+	// returned when the value does not fit, including floats with a fractional part
+	// converted to integers and integers not exactly representable by a float.
+	ConversionNarrowing
+
+	// ConversionString allows conversions between strings and bool or numeric kinds.
+	ConversionString
+)
+
+// OverflowError is returned when a value does not fit the destination kind.
+// It wraps ErrOverflow.
+type OverflowError struct {
+	// Field is the path of the destination field
+	Field string
+
+	// Value is the source value
+	Value any
+
+	// Type is the destination type
+	Type reflect.Type
+}
+
+func (e *OverflowError) Error() string {
+	return fmt.Sprintf("value %v of field %s overflows %s", e.Value, e.Field, e.Type)
+}
+
+func (e *OverflowError) Unwrap() error {
+	return ErrOverflow
+}
+
+func isConvertibleKind(kind reflect.Kind) bool {
+	return kind == reflect.Bool || kind == reflect.String || isIntKind(kind) || isUintKind(kind) ||
+		isFloatKind(kind) || isComplexKind(kind)
+}
+
+func isIntKind(kind reflect.Kind) bool {
+	return kind >= reflect.Int && kind <= reflect.Int64
+}
+
+func isUintKind(kind reflect.Kind) bool {
+	return kind >= reflect.Uint && kind <= reflect.Uint64
+}
+
+func isFloatKind(kind reflect.Kind) bool {
+	return kind == reflect.Float32 || kind == reflect.Float64
+}
+
+func isComplexKind(kind reflect.Kind) bool {
+	return kind == reflect.Complex64 || kind == reflect.Complex128
+}
+
+// convertValue sets dst to src converted to the kind of dst, if the configured
+// conversions allow it.
+func (m *Mapper) convertValue(src reflect.Value, dst reflect.Value) error {
+	if src.Kind() == reflect.String || dst.Kind() == reflect.String {
+		if m.cfg.conversions&ConversionString == 0 {
+			return ErrMismatchType
+		}
+		return convertString(src, dst)
+	}
+	if src.Kind() == reflect.Bool || dst.Kind() == reflect.Bool {
+		return ErrMismatchType
+	}
+
+	if isWidening(src.Type(), dst.Type()) {
+		setNumber(src, dst)
+		return nil
+	}
+	if m.cfg.conversions&ConversionNarrowing == 0 || isComplexKind(src.Kind()) || isComplexKind(dst.Kind()) {
+		return ErrMismatchType
+	}
+	if overflows(src, dst.Type()) {
+		return &OverflowError{Value: src.Interface(), Type: dst.Type()}
+	}
+	setNumber(src, dst)
+	return nil
+}
+
+// isWidening returns true if every value of src can be represented by dst.
+func isWidening(src reflect.Type, dst reflect.Type) bool {
+	srcKind, dstKind := src.Kind(), dst.Kind()
+	switch {
+	case isIntKind(srcKind) && isIntKind(dstKind), isUintKind(srcKind) && isUintKind(dstKind):
+		return src.Bits() <= dst.Bits()
+	case isUintKind(srcKind) && isIntKind(dstKind):
+		return src.Bits() < dst.Bits()
+	case (isIntKind(srcKind) || isUintKind(srcKind)) && isFloatKind(dstKind):
+		return src.Bits() <= mantissaBits(dst)
+	case (isIntKind(srcKind) || isUintKind(srcKind)) && isComplexKind(dstKind):
+		return src.Bits() <= mantissaBits(dst)
+	case isFloatKind(srcKind) && (isFloatKind(dstKind) || isComplexKind(dstKind)):
+		return mantissaBits(src) <= mantissaBits(dst)
+	case isComplexKind(srcKind) && isComplexKind(dstKind):
+		return src.Bits() <= dst.Bits()
+	}
+	return false
+}
+
+// mantissaBits returns the number of bits of the significand of a float or complex type.
+func mantissaBits(t reflect.Type) int {
+	if t.Kind() == reflect.Float32 || t.Kind() == reflect.Complex64 {
+		return 24
+	}
+	return 53
+}
+
+// overflows returns true if the numeric value src cannot be represented by dst.
+func overflows(src reflect.Value, dst reflect.Type) bool {
+	srcKind, dstKind := src.Kind(), dst.Kind()
+	switch {
+	case isIntKind(srcKind) && isIntKind(dstKind):
+		return reflect.Zero(dst).OverflowInt(src.Int())
+	case isIntKind(srcKind) && isUintKind(dstKind):
+		return src.Int() < 0 || reflect.Zero(dst).OverflowUint(uint64(src.Int()))
+	case isUintKind(srcKind) && isIntKind(dstKind):
+		return src.Uint() > math.MaxInt64 || reflect.Zero(dst).OverflowInt(int64(src.Uint()))
+	case isUintKind(srcKind) && isUintKind(dstKind):
+		return reflect.Zero(dst).OverflowUint(src.Uint())
+	case isFloatKind(srcKind) && isFloatKind(dstKind):
+		return reflect.Zero(dst).OverflowFloat(src.Float())
+	case isFloatKind(srcKind) && isIntKind(dstKind):
+		f := src.Float()
+		return f != math.Trunc(f) || f < -math.Exp2(float64(dst.Bits()-1)) || f >= math.Exp2(float64(dst.Bits()-1))
+	case isFloatKind(srcKind) && isUintKind(dstKind):
+		f := src.Float()
+		return f != math.Trunc(f) || f < 0 || f >= math.Exp2(float64(dst.Bits()))
+	case isIntKind(srcKind) && isFloatKind(dstKind):
+		f := roundFloat(float64(src.Int()), dst)
+		return f < -math.Exp2(63) || f >= math.Exp2(63) || int64(f) != src.Int()
+	case isUintKind(srcKind) && isFloatKind(dstKind):
+		f := roundFloat(float64(src.Uint()), dst)
+		return f >= math.Exp2(64) || uint64(f) != src.Uint()
+	}
+	return false
+}
+
+// roundFloat rounds f to the precision of the float type t.
+func roundFloat(f float64, t reflect.Type) float64 {
+	if t.Kind() == reflect.Float32 {
+		return float64(float32(f))
+	}
+	return f
+}
+
+// setNumber sets the numeric value src to dst. The value must fit dst.
+func setNumber(src reflect.Value, dst reflect.Value) {
+	switch {
+	case isIntKind(dst.Kind()):
+		if isFloatKind(src.Kind()) {
+			dst.SetInt(int64(src.Float()))
+		} else if isUintKind(src.Kind()) {
+			dst.SetInt(int64(src.Uint()))
+		} else {
+			dst.SetInt(src.Int())
+		}
+	case isUintKind(dst.Kind()):
+		if isFloatKind(src.Kind()) {
+			dst.SetUint(uint64(src.Float()))
+		} else if isIntKind(src.Kind()) {
+			dst.SetUint(uint64(src.Int()))
+		} else {
+			dst.SetUint(src.Uint())
+		}
+	case isFloatKind(dst.Kind()):
+		if isIntKind(src.Kind()) {
+			dst.SetFloat(float64(src.Int()))
+		} else if isUintKind(src.Kind()) {
+			dst.SetFloat(float64(src.Uint()))
+		} else {
+			dst.SetFloat(src.Float())
+		}
+	case isComplexKind(dst.Kind()):
+		if isIntKind(src.Kind()) {
+			dst.SetComplex(complex(float64(src.Int()), 0))
+		} else if isUintKind(src.Kind()) {
+			dst.SetComplex(complex(float64(src.Uint()), 0))
+		} else if isFloatKind(src.Kind()) {
+			dst.SetComplex(complex(src.Float(), 0))
+		} else {
+			dst.SetComplex(src.Complex())
+		}
+	}
+}
+
+// convertString converts between strings and bool or numeric kinds.
+func convertString(src reflect.Value, dst reflect.Value) error {
+	if dst.Kind() == reflect.String {
+		switch {
+		case src.Kind() == reflect.Bool:
+			dst.SetString(strconv.FormatBool(src.Bool()))
+		case isIntKind(src.Kind()):
+			dst.SetString(strconv.FormatInt(src.Int(), 10))
+		case isUintKind(src.Kind()):
+			dst.SetString(strconv.FormatUint(src.Uint(), 10))
+		case isFloatKind(src.Kind()):
+			dst.SetString(strconv.FormatFloat(src.Float(), 'g', -1, src.Type().Bits()))
+		case isComplexKind(src.Kind()):
+			dst.SetString(strconv.FormatComplex(src.Complex(), 'g', -1, src.Type().Bits()))
+		}
+		return nil
+	}
+
+	s := src.String()
+	var err error
+	switch {
+	case dst.Kind() == reflect.Bool:
+		var b bool
+		b, err = strconv.ParseBool(s)
+		if err == nil {
+			dst.SetBool(b)
+		}
+	case isIntKind(dst.Kind()):
+		var i int64
+		i, err = strconv.ParseInt(s, 10, dst.Type().Bits())
+		if err == nil {
+			dst.SetInt(i)
+		}
+	case isUintKind(dst.Kind()):
+		var u uint64
+		u, err = strconv.ParseUint(s, 10, dst.Type().Bits())
+		if err == nil {
+			dst.SetUint(u)
+		}
+	case isFloatKind(dst.Kind()):
+		var f float64
+		f, err = strconv.ParseFloat(s, dst.Type().Bits())
+		if err == nil {
+			dst.SetFloat(f)
+		}
+	case isComplexKind(dst.Kind()):
+		var c complex128
+		c, err = strconv.ParseComplex(s, dst.Type().Bits())
+		if err == nil {
+			dst.SetComplex(c)
+		}
+	}
+
+	if numErr, ok := err.(*strconv.NumError); ok && numErr.Err == strconv.ErrRange {
+		return &OverflowError{Value: s, Type: dst.Type()}
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrMismatchType, err)
+	}
+	return nil
+}
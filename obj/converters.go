@@ -0,0 +1,158 @@
+package obj
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+// RegisterStandardConverters registers converters for commonly mapped types of
+// the standard library. See RegisterTimeConverters, RegisterSQLConverters,
+// RegisterJSONConverters and RegisterBigConverters for the registered converters.
+func RegisterStandardConverters(mapper *Mapper) error {
+	return errors.Join(
+		RegisterTimeConverters(mapper),
+		RegisterSQLConverters(mapper),
+		RegisterJSONConverters(mapper),
+		RegisterBigConverters(mapper),
+	)
+}
+
+// RegisterTimeConverters registers converters between:
+//   - time.Time and string, formatted as RFC 3339
+//   - time.Time and int64, as unix seconds
+//   - time.Duration and string, formatted by [time.Duration.String]
+func RegisterTimeConverters(mapper *Mapper) error {
+	return errors.Join(
+		RegisterConverter(mapper, func(t time.Time) (string, error) {
+			return t.Format(time.RFC3339Nano), nil
+		}),
+		RegisterConverter(mapper, func(s string) (time.Time, error) {
+			t, err := time.Parse(time.RFC3339Nano, s)
+			if err != nil {
+				return time.Time{}, fmt.Errorf("%w: %w", ErrMismatchType, err)
+			}
+			return t, nil
+		}),
+		RegisterConverter(mapper, func(t time.Time) (int64, error) {
+			return t.Unix(), nil
+		}),
+		RegisterConverter(mapper, func(unix int64) (time.Time, error) {
+			return time.Unix(unix, 0).UTC(), nil
+		}),
+		RegisterConverter(mapper, func(d time.Duration) (string, error) {
+			return d.String(), nil
+		}),
+		RegisterConverter(mapper, func(s string) (time.Duration, error) {
+			d, err := time.ParseDuration(s)
+			if err != nil {
+				return 0, fmt.Errorf("%w: %w", ErrMismatchType, err)
+			}
+			return d, nil
+		}),
+	)
+}
+
+// RegisterSQLConverters registers converters between the sql.Null* types and
+// pointers to their values, e.g. sql.NullString and *string. Null values are
+// converted to nil pointers and vice versa.
+func RegisterSQLConverters(mapper *Mapper) error {
+	return errors.Join(
+		registerSQLNull(mapper,
+			func(n sql.NullString) (string, bool) { return n.String, n.Valid },
+			func(v string, valid bool) sql.NullString { return sql.NullString{String: v, Valid: valid} }),
+		registerSQLNull(mapper,
+			func(n sql.NullInt64) (int64, bool) { return n.Int64, n.Valid },
+			func(v int64, valid bool) sql.NullInt64 { return sql.NullInt64{Int64: v, Valid: valid} }),
+		registerSQLNull(mapper,
+			func(n sql.NullInt32) (int32, bool) { return n.Int32, n.Valid },
+			func(v int32, valid bool) sql.NullInt32 { return sql.NullInt32{Int32: v, Valid: valid} }),
+		registerSQLNull(mapper,
+			func(n sql.NullInt16) (int16, bool) { return n.Int16, n.Valid },
+			func(v int16, valid bool) sql.NullInt16 { return sql.NullInt16{Int16: v, Valid: valid} }),
+		registerSQLNull(mapper,
+			func(n sql.NullByte) (byte, bool) { return n.Byte, n.Valid },
+			func(v byte, valid bool) sql.NullByte { return sql.NullByte{Byte: v, Valid: valid} }),
+		registerSQLNull(mapper,
+			func(n sql.NullFloat64) (float64, bool) { return n.Float64, n.Valid },
+			func(v float64, valid bool) sql.NullFloat64 { return sql.NullFloat64{Float64: v, Valid: valid} }),
+		registerSQLNull(mapper,
+			func(n sql.NullBool) (bool, bool) { return n.Bool, n.Valid },
+			func(v bool, valid bool) sql.NullBool { return sql.NullBool{Bool: v, Valid: valid} }),
+		registerSQLNull(mapper,
+			func(n sql.NullTime) (time.Time, bool) { return n.Time, n.Valid },
+			func(v time.Time, valid bool) sql.NullTime { return sql.NullTime{Time: v, Valid: valid} }),
+	)
+}
+
+// registerSQLNull registers converters between the sql.Null* type nullT and *valueT.
+func registerSQLNull[nullT any, valueT any](mapper *Mapper, get func(n nullT) (valueT, bool),
+	newNull func(v valueT, valid bool) nullT) error {
+	return errors.Join(
+		RegisterConverter(mapper, func(n nullT) (*valueT, error) {
+			v, valid := get(n)
+			if !valid {
+				return nil, nil
+			}
+			return &v, nil
+		}),
+		RegisterConverter(mapper, func(v *valueT) (nullT, error) {
+			if v == nil {
+				var zero valueT
+				return newNull(zero, false), nil
+			}
+			return newNull(*v, true), nil
+		}),
+	)
+}
+
+// RegisterJSONConverters registers converters between json.RawMessage and
+// map[string]any. An empty or null json.RawMessage is converted to a nil map
+// and vice versa.
+func RegisterJSONConverters(mapper *Mapper) error {
+	return errors.Join(
+		RegisterConverter(mapper, func(raw json.RawMessage) (map[string]any, error) {
+			var m map[string]any
+			if len(raw) == 0 {
+				return m, nil
+			}
+			err := json.Unmarshal(raw, &m)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %w", ErrMismatchType, err)
+			}
+			return m, nil
+		}),
+		RegisterConverter(mapper, func(m map[string]any) (json.RawMessage, error) {
+			if m == nil {
+				return nil, nil
+			}
+			return json.Marshal(m)
+		}),
+	)
+}
+
+// RegisterBigConverters registers converters between *big.Int and its base 10
+// string representation. A nil *big.Int is converted to an empty string and vice versa.
+func RegisterBigConverters(mapper *Mapper) error {
+	return errors.Join(
+		RegisterConverter(mapper, func(i *big.Int) (string, error) {
+			if i == nil {
+				return "", nil
+			}
+			return i.String(), nil
+		}),
+		RegisterConverter(mapper, func(s string) (*big.Int, error) {
+			if s == "" {
+				return nil, nil
+			}
+			i, ok := new(big.Int).SetString(s, 10)
+			if !ok {
+				return nil, fmt.Errorf("%w: invalid integer %q", ErrMismatchType, s)
+			}
+			return i, nil
+		}),
+	)
+}
@@ -0,0 +1,225 @@
+package obj
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+)
+
+// MappingKind describes where the value of a destination field comes from.
+type MappingKind string
+
+const (
+	// MappingField reads the source field with the same name.
+	MappingField MappingKind = "field"
+
+	// MappingRenamed reads a source field with another name, renamed by a tag or
+	// FieldMapConfig.
+	MappingRenamed MappingKind = "renamed"
+
+	// MappingFlattened reads a field of a struct nested in the source, or a source
+	// field for a field of a struct nested in the destination, see WithFlattening.
+	MappingFlattened MappingKind = "flattened"
+
+	// MappingGetter calls a getter of the source, see NamingStrategy.
+	MappingGetter MappingKind = "getter"
+
+	// MappingKey reads a key of the source map.
+	MappingKey MappingKind = "key"
+
+	// MappingAccessor calls an accessor registered with RegisterFieldAccessor.
+	MappingAccessor MappingKind = "accessor"
+
+	// MappingFunc calls FieldMapConfig.GetDestinationValue.
+	MappingFunc MappingKind = "func"
+
+	// MappingUnmapped leaves the destination field untouched as there is no source.
+	MappingUnmapped MappingKind = "unmapped"
+)
+
+// FieldMapping describes how a destination field is populated.
+type FieldMapping struct {
+	// Destination is the name of the destination field or map key, or the path
+	// of a field of a nested struct, e.g. Address.City
+	Destination string `json:"destination"`
+
+	// DestinationType is the type of the destination field
+	DestinationType string `json:"destinationType"`
+
+	// Setter is the name of the setter populating the destination, empty if
+	// the field is set directly
+	Setter string `json:"setter,omitempty"`
+
+	// Kind describes where the value comes from
+	Kind MappingKind `json:"kind"`
+
+	// Source is the name or path of the source field, the name of the getter or
+	// the map key, empty if unmapped
+	Source string `json:"source,omitempty"`
+
+	// SourceType is the type of the source value, empty if unknown
+	SourceType string `json:"sourceType,omitempty"`
+
+	// Converter indicates that a converter registered for the source and
+	// destination types is used
+	Converter bool `json:"converter,omitempty"`
+}
+
+// MappingPlan describes how a source type is mapped to a destination type. It can
+// be marshaled to JSON for snapshot tests, or printed for code reviews.
+type MappingPlan struct {
+	SourceType      string         `json:"sourceType"`
+	DestinationType string         `json:"destinationType"`
+	Fields          []FieldMapping `json:"fields"`
+}
+
+// String formats the plan as a table with a line per destination field, e.g.
+//
+//	obj.User -> obj.UserDTO
+//	  FullName  string  <- Name    string  renamed
+//	  Age       int     <- GetAge  int     getter, SetAge
+func (p *MappingPlan) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s -> %s\n", p.SourceType, p.DestinationType)
+	w := tabwriter.NewWriter(&sb, 0, 4, 2, ' ', 0)
+	for _, field := range p.Fields {
+		details := []string{string(field.Kind)}
+		if field.Setter != "" {
+			details = append(details, field.Setter)
+		}
+		if field.Converter {
+			details = append(details, "converter")
+		}
+		fmt.Fprintf(w, "  %s\t%s\t<-\t%s\t%s\t%s\n", field.Destination, field.DestinationType,
+			field.Source, field.SourceType, strings.Join(details, ", "))
+	}
+	w.Flush()
+	return sb.String()
+}
+
+// Explain describes how the mapper maps sourceT to destinationT, field by field,
+// using the same plan as Map. Struct fields are described but not expanded, call
+// Explain for their types as well.
+// Sample usage:
+//
+//	plan, err := obj.Explain[UserEntity, UserDTO](mapper)
+//	if err != nil {
+//		panic(err)
+//	}
+//	fmt.Print(plan)
+func Explain[sourceT any, destinationT any](mapper *Mapper) (*MappingPlan, error) {
+	srcType := reflect.TypeFor[sourceT]()
+	dstType := reflect.TypeFor[destinationT]()
+	if !isFieldMappable(srcType) || !isFieldMappable(dstType) ||
+		(srcType.Kind() == reflect.Map && dstType.Kind() == reflect.Map) {
+		return nil, fmt.Errorf("sourceT and destinationT must be structs, or a struct and a map with string keys")
+	}
+
+	plan := mapper.structPlan(srcType, dstType)
+	explained := &MappingPlan{
+		SourceType:      srcType.String(),
+		DestinationType: dstType.String(),
+	}
+	if dstType.Kind() == reflect.Map {
+		for _, field := range plan.fields {
+			explained.Fields = append(explained.Fields,
+				mapper.explainField(field, "", srcType, dstType.Elem(), false))
+		}
+		return explained, nil
+	}
+
+	explained.Fields = mapper.explainFields(plan.fields, "", srcType, dstType, false)
+	for _, setter := range plan.setters {
+		mapping := mapper.explainSource(setter.source, setter.fieldMap, setter.name, srcType, setter.paramType)
+		mapping.Destination = setter.name
+		mapping.DestinationType = setter.paramType.String()
+		mapping.Setter = reflect.PointerTo(dstType).Method(setter.method).Name
+		explained.Fields = append(explained.Fields, mapping)
+	}
+	return explained, nil
+}
+
+func (m *Mapper) explainFields(fields []fieldPlan, prefix string, srcType reflect.Type, dstType reflect.Type,
+	flattened bool) []FieldMapping {
+	var mappings []FieldMapping
+	for _, field := range fields {
+		dstField := dstType.Field(field.index)
+		switch {
+		case field.nested != nil && field.promoted:
+			mappings = append(mappings, m.explainFields(field.nested.fields, prefix, srcType,
+				structType(dstField.Type), flattened)...)
+		case field.nested != nil:
+			mappings = append(mappings, m.explainFields(field.nested.fields, prefix+field.name+".", srcType,
+				structType(dstField.Type), true)...)
+		default:
+			mappings = append(mappings, m.explainField(field, prefix, srcType, dstField.Type, flattened))
+		}
+	}
+	return mappings
+}
+
+func (m *Mapper) explainField(field fieldPlan, prefix string, srcType reflect.Type, dstType reflect.Type,
+	flattened bool) FieldMapping {
+	mapping := m.explainSource(field.source, field.fieldMap, field.name, srcType, dstType)
+	mapping.Destination = prefix + field.name
+	mapping.DestinationType = dstType.String()
+	if flattened && (mapping.Kind == MappingField || mapping.Kind == MappingRenamed) {
+		mapping.Kind = MappingFlattened
+	}
+	return mapping
+}
+
+// explainSource describes the source of a destination field or setter named name.
+func (m *Mapper) explainSource(source sourcePlan, fieldMap *FieldMapConfig, name string,
+	srcType reflect.Type, dstType reflect.Type) FieldMapping {
+	mapping := FieldMapping{Kind: MappingUnmapped}
+	switch {
+	case source.accessor != nil:
+		mapping.Kind = MappingAccessor
+		mapping.Source = name
+	case source.mapKey.IsValid():
+		mapping.Kind = MappingKey
+		mapping.Source = source.mapKey.String()
+	case source.fieldIndex != nil:
+		mapping.Source = fieldPath(srcType, source.fieldIndex)
+		switch {
+		case strings.Contains(mapping.Source, "."):
+			mapping.Kind = MappingFlattened
+		case mapping.Source == name:
+			mapping.Kind = MappingField
+		default:
+			mapping.Kind = MappingRenamed
+		}
+	case source.getter >= 0:
+		mapping.Kind = MappingGetter
+		mapping.Source = source.getterMethod(srcType).Name
+	}
+
+	if srcValueType := source.valueType(srcType); srcValueType != nil {
+		mapping.SourceType = srcValueType.String()
+		_, mapping.Converter = m.cfg.converters[structMapKey{source: srcValueType, destination: dstType}]
+	}
+	if fieldMap != nil && fieldMap.GetDestinationValue != nil {
+		mapping.Kind = MappingFunc
+		mapping.Converter = false
+	}
+	return mapping
+}
+
+// fieldPath returns the path of the field at index in t, e.g. Address.City.
+// Embedded structs are omitted as their fields are promoted.
+func fieldPath(t reflect.Type, index []int) string {
+	var names []string
+	for i, fieldIndex := range index {
+		if t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		field := t.Field(fieldIndex)
+		if !field.Anonymous || i == len(index)-1 {
+			names = append(names, field.Name)
+		}
+		t = field.Type
+	}
+	return strings.Join(names, ".")
+}
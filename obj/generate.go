@@ -0,0 +1,446 @@
+package obj
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/format"
+	"io"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// GeneratePair declares a source and destination struct type pair for which
+// Generate writes a mapping function.
+type GeneratePair struct {
+	Source      reflect.Type
+	Destination reflect.Type
+
+	// FuncName is the name of the generated function, Map<Source>To<Destination> if empty
+	FuncName string
+}
+
+// PairOf returns the GeneratePair of sourceT and destinationT.
+func PairOf[sourceT any, destinationT any]() GeneratePair {
+	return GeneratePair{
+		Source:      reflect.TypeFor[sourceT](),
+		Destination: reflect.TypeFor[destinationT](),
+	}
+}
+
+func (p GeneratePair) funcName() string {
+	if p.FuncName != "" {
+		return p.FuncName
+	}
+	return "Map" + p.Source.Name() + "To" + p.Destination.Name()
+}
+
+// GenerateConfig contains the configuration of Generate
+type GenerateConfig struct {
+	// PackageName is the name of the package of the generated file
+	PackageName string
+
+	// PackagePath is the import path of the package of the generated file. Types
+	// of other packages are qualified with their package.
+	PackagePath string
+
+	// Pairs are the type pairs to generate mapping functions for
+	Pairs []GeneratePair
+}
+
+// Generate writes the Go source of a file with a function mapping the source to
+// the destination of each pair the way mapper.Map does, e.g.
+//
+//	func MapUserEntityToUserDTO(mapper *obj.Mapper, src *UserEntity, dst *UserDTO) error
+//
+// Fields of basic types, and struct fields of types of other pairs, are mapped
+// with plain Go code. Other fields, e.g. slices or fields with a converter, are
+// mapped with MapField, which uses reflection. A generated function calls
+// mapper.Map instead when the mapper collects errors or preserves references, or
+// in strict mode when the pair has unmapped fields.
+//
+// The generated code reflects the configuration of mapper when Generate is
+// called. Use VerifyGenerated in tests to check that it is up to date.
+func Generate(w io.Writer, mapper *Mapper, cfg GenerateConfig) error {
+	g := &generator{
+		mapper:  mapper,
+		cfg:     cfg,
+		imports: make(map[string]string),
+		funcs:   make(map[structMapKey]string),
+	}
+	for _, pair := range cfg.Pairs {
+		if pair.Source == nil || pair.Destination == nil ||
+			pair.Source.Kind() != reflect.Struct || pair.Destination.Kind() != reflect.Struct {
+			return fmt.Errorf("source and destination of pairs must be structs")
+		}
+		if pair.Source.Name() == "" || pair.Destination.Name() == "" {
+			return fmt.Errorf("source and destination of pairs must be named types")
+		}
+		g.funcs[structMapKey{source: pair.Source, destination: pair.Destination}] = pair.funcName()
+	}
+	for _, pair := range cfg.Pairs {
+		g.generatePair(pair)
+	}
+
+	var src bytes.Buffer
+	src.WriteString("// Code generated by goeasy-mapgen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&src, "package %s\n\n", cfg.PackageName)
+	if len(g.imports) > 0 {
+		importPaths := make([]string, 0, len(g.imports))
+		for importPath := range g.imports {
+			importPaths = append(importPaths, importPath)
+		}
+		sort.Strings(importPaths)
+		src.WriteString("import (\n")
+		for _, importPath := range importPaths {
+			fmt.Fprintf(&src, "\t%s %q\n", g.imports[importPath], importPath)
+		}
+		src.WriteString(")\n\n")
+	}
+	src.Write(g.body.Bytes())
+
+	formatted, err := format.Source(src.Bytes())
+	if err != nil {
+		return fmt.Errorf("failed to format generated code: %w", err)
+	}
+	_, err = w.Write(formatted)
+	return err
+}
+
+// generator writes the mapping functions of Generate.
+type generator struct {
+	mapper  *Mapper
+	cfg     GenerateConfig
+	imports map[string]string       // import path -> package name
+	funcs   map[structMapKey]string // type pair -> generated function name
+	body    bytes.Buffer
+}
+
+func (g *generator) generatePair(pair GeneratePair) {
+	name := pair.funcName()
+	fmt.Fprintf(&g.body, "// %s maps src to dst like mapper.Map(src, dst).\n", name)
+	fmt.Fprintf(&g.body, "func %s(mapper *%s, src *%s, dst *%s) error {\n", name,
+		g.typeName(reflect.TypeFor[Mapper]()), g.typeName(pair.Source), g.typeName(pair.Destination))
+
+	plan := g.mapper.structPlan(pair.Source, pair.Destination)
+	cfg := g.mapper.cfg
+	if cfg.collectErrors || cfg.preserveReferences || (cfg.strict && plan.unmapped != nil) {
+		g.body.WriteString("return mapper.Map(src, dst)\n}\n\n")
+		return
+	}
+
+	g.generateFields(plan.fields, pair.Source, pair.Destination, "", true)
+	for _, setter := range plan.setters {
+		method := reflect.PointerTo(pair.Destination).Method(setter.method).Name
+		srcExpr, ok := g.sourceExpr(setter.source, pair.Source, setter.paramType)
+		if !ok || setter.fluent || (setter.fieldMap != nil && setter.fieldMap.GetDestinationValue != nil) {
+			g.generateMapField(method)
+			continue
+		}
+		g.generateAssign(srcExpr, "dst."+method+"(%s)", setter.paramType, g.mapper.mergeStrategy(setter.fieldMap), false)
+	}
+	g.body.WriteString("return nil\n}\n\n")
+}
+
+// generateFields writes the mapping of fields of dstType. prefix is the path of
+// the nested struct the fields belong to, settable is false if the path goes
+// through a pointer, which MapField allocates.
+func (g *generator) generateFields(fields []fieldPlan, srcType reflect.Type, dstType reflect.Type, prefix string,
+	settable bool) {
+	for _, field := range fields {
+		dstField := dstType.Field(field.index)
+		nestedSettable := settable && dstField.Type.Kind() == reflect.Struct
+		switch {
+		case field.nested != nil && field.promoted:
+			g.generateFields(field.nested.fields, srcType, structType(dstField.Type), prefix, nestedSettable)
+		case field.nested != nil:
+			g.generateFields(field.nested.fields, srcType, structType(dstField.Type), prefix+field.name+".",
+				nestedSettable)
+		default:
+			g.generateField(field, srcType, dstField.Type, prefix, settable)
+		}
+	}
+}
+
+func (g *generator) generateField(field fieldPlan, srcType reflect.Type, dstType reflect.Type, prefix string,
+	settable bool) {
+	name := prefix + field.name
+	hasFunc := field.fieldMap != nil && field.fieldMap.GetDestinationValue != nil
+	if !field.source.found() && !hasFunc {
+		return // left untouched by Map
+	}
+
+	strategy := g.mapper.mergeStrategy(field.fieldMap)
+	srcExpr, ok := g.sourceExpr(field.source, srcType, dstType)
+	if !ok || hasFunc || !settable || field.accessor != nil {
+		g.generateMapField(name)
+		return
+	}
+
+	srcValueType := field.source.valueType(srcType)
+	if isBasicKind(dstType.Kind()) {
+		g.generateAssign(srcExpr, "dst."+name+" = %s", dstType, strategy, field.omitEmpty)
+		return
+	}
+
+	funcName, ok := g.funcs[structMapKey{source: srcValueType, destination: dstType}]
+	if !ok || field.source.fieldIndex == nil || field.omitEmpty || strategy != MergeOverwrite {
+		g.generateMapField(name)
+		return
+	}
+	fmt.Fprintf(&g.body, "if err := %s(mapper, &%s, &dst.%s); err != nil {\nreturn err\n}\n", funcName, srcExpr, name)
+}
+
+// generateAssign writes the assignment of srcExpr with format, skipping zero
+// values if required by strategy or omitEmpty.
+func (g *generator) generateAssign(srcExpr string, format string, t reflect.Type, strategy MergeStrategy,
+	omitEmpty bool) {
+	if strategy != MergeSkipZero && !omitEmpty {
+		fmt.Fprintf(&g.body, format+"\n", srcExpr)
+		return
+	}
+	fmt.Fprintf(&g.body, "if v := %s; v != %s {\n"+format+"\n}\n", srcExpr, zeroLiteral(t), "v")
+}
+
+func (g *generator) generateMapField(name string) {
+	mapField := "MapField"
+	if g.cfg.PackagePath != reflect.TypeFor[Mapper]().PkgPath() {
+		mapField = g.packageName(reflect.TypeFor[Mapper]().PkgPath()) + ".MapField"
+	}
+	fmt.Fprintf(&g.body, "if err := %s(mapper, src, dst, %q); err != nil {\nreturn err\n}\n", mapField, name)
+}
+
+// sourceExpr returns the expression reading source from src if it can be
+// assigned to dstType without reflection. Returns false otherwise.
+func (g *generator) sourceExpr(source sourcePlan, srcType reflect.Type, dstType reflect.Type) (string, bool) {
+	srcValueType := source.valueType(srcType)
+	if srcValueType == nil || source.accessor != nil {
+		return "", false
+	}
+	if _, ok := g.mapper.cfg.converters[structMapKey{source: srcValueType, destination: dstType}]; ok {
+		return "", false
+	}
+	if srcValueType != dstType {
+		if _, ok := g.funcs[structMapKey{source: srcValueType, destination: dstType}]; !ok {
+			return "", false
+		}
+	}
+	if !isBasicKind(dstType.Kind()) && dstType.Kind() != reflect.Struct {
+		return "", false
+	}
+
+	if source.getter >= 0 {
+		return "src." + source.getterMethod(srcType).Name + "()", true
+	}
+	t := srcType
+	for _, index := range source.fieldIndex[:len(source.fieldIndex)-1] {
+		t = t.Field(index).Type
+		if t.Kind() == reflect.Pointer {
+			return "", false // nil embedded or nested pointers are skipped by Map
+		}
+	}
+	return "src." + fieldPath(srcType, source.fieldIndex), true
+}
+
+// typeName returns the name of t qualified with its package if it is not the
+// package of the generated file.
+func (g *generator) typeName(t reflect.Type) string {
+	if t.PkgPath() == g.cfg.PackagePath {
+		return t.Name()
+	}
+	return g.packageName(t.PkgPath()) + "." + t.Name()
+}
+
+// packageName returns the name the package importPath is imported as.
+func (g *generator) packageName(importPath string) string {
+	if name, ok := g.imports[importPath]; ok {
+		return name
+	}
+	name := path.Base(importPath)
+	for _, imported := range g.imports {
+		if imported == name {
+			name = fmt.Sprintf("%s%d", name, len(g.imports))
+			break
+		}
+	}
+	g.imports[importPath] = name
+	return name
+}
+
+func isBasicKind(kind reflect.Kind) bool {
+	return kind == reflect.Bool || kind == reflect.String || isIntKind(kind) || isUintKind(kind) ||
+		isFloatKind(kind) || isComplexKind(kind)
+}
+
+func zeroLiteral(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Bool:
+		return "false"
+	case reflect.String:
+		return `""`
+	}
+	return "0"
+}
+
+// MapField maps the destination field, or Set* method, named field of dst from
+// src the way Map does. Fields of nested structs are named after their path, e.g.
+// Address.City. It is called by code written by Generate for fields which can't
+// be mapped without reflection.
+func MapField[sourceT any, destinationT any](mapper *Mapper, src *sourceT, dst *destinationT, field string) error {
+	srcValue := reflect.ValueOf(src).Elem()
+	dstValue := reflect.ValueOf(dst).Elem()
+	if !isFieldMappable(srcValue.Type()) || dstValue.Kind() != reflect.Struct {
+		return fmt.Errorf("sourceT must be a struct or a map with string keys and destinationT a struct")
+	}
+
+	plan := mapper.structPlan(srcValue.Type(), dstValue.Type())
+	state := mapState{path: make([]pathSegment, 0, pathCapacity), collectErrors: mapper.cfg.collectErrors}
+	var err error
+	if chain := findFieldPlan(plan.fields, field); chain != nil {
+		err = mapper.mapStructFields(&state, pruneFieldPlan(chain), srcValue, dstValue)
+	} else if setter, ok := findSetterPlan(plan.setters, dstValue.Type(), field); ok {
+		err = mapper.mapStructSetters(&state, &structPlan{setters: []setterPlan{setter}}, srcValue, dstValue)
+	} else {
+		return fmt.Errorf("%w: %s", ErrFieldNotFound, field)
+	}
+	if err != nil {
+		return err
+	}
+	return errors.Join(state.errs...)
+}
+
+// findFieldPlan returns the plans of the nested structs leading to the field
+// named name, ending with the plan of the field itself. Returns nil if not found.
+func findFieldPlan(fields []fieldPlan, name string) []fieldPlan {
+	for _, field := range fields {
+		var chain []fieldPlan
+		switch {
+		case field.nested == nil && field.name == name:
+			return []fieldPlan{field}
+		case field.nested != nil && field.promoted:
+			chain = findFieldPlan(field.nested.fields, name)
+		case field.nested != nil:
+			if rest, ok := strings.CutPrefix(name, field.name+"."); ok {
+				chain = findFieldPlan(field.nested.fields, rest)
+			}
+		}
+		if chain != nil {
+			return append([]fieldPlan{field}, chain...)
+		}
+	}
+	return nil
+}
+
+// pruneFieldPlan returns the plan mapping only the last field of chain.
+func pruneFieldPlan(chain []fieldPlan) *structPlan {
+	plan := &structPlan{fields: []fieldPlan{chain[len(chain)-1]}}
+	for i := len(chain) - 2; i >= 0; i-- {
+		field := chain[i]
+		field.nested = plan
+		plan = &structPlan{fields: []fieldPlan{field}}
+	}
+	return plan
+}
+
+func findSetterPlan(setters []setterPlan, dstType reflect.Type, method string) (setterPlan, bool) {
+	for _, setter := range setters {
+		if reflect.PointerTo(dstType).Method(setter.method).Name == method {
+			return setter, true
+		}
+	}
+	return setterPlan{}, false
+}
+
+// VerifyGenerated checks that generated, a function written by Generate, maps
+// sourceT to destinationT the same way mapper.Map does. Sample source values,
+// zero and with every exported field populated, are mapped to new and populated
+// destination values by both. Returns an error describing the first difference.
+// Sample usage:
+//
+//	func TestGeneratedMappers(t *testing.T) {
+//		if err := obj.VerifyGenerated(mapper, MapUserEntityToUserDTO); err != nil {
+//			t.Error(err)
+//		}
+//	}
+func VerifyGenerated[sourceT any, destinationT any](mapper *Mapper,
+	generated func(mapper *Mapper, src *sourceT, dst *destinationT) error) error {
+	for _, populateSrc := range []bool{false, true} {
+		for _, populateDst := range []bool{false, true} {
+			var src sourceT
+			var want, got destinationT
+			if populateSrc {
+				populateSample(reflect.ValueOf(&src).Elem(), 0)
+			}
+			if populateDst {
+				populateSample(reflect.ValueOf(&want).Elem(), 0)
+				populateSample(reflect.ValueOf(&got).Elem(), 0)
+			}
+
+			wantErr := mapper.Map(&src, &want)
+			gotErr := generated(mapper, &src, &got)
+			if (wantErr == nil) != (gotErr == nil) {
+				return fmt.Errorf("generated mapping of %T to %T returned %v, Map returned %v", src, got, gotErr, wantErr)
+			}
+			if !reflect.DeepEqual(want, got) {
+				return fmt.Errorf("generated mapping of %+v to %T differs from Map:\ngenerated: %+v\nMap:       %+v",
+					src, got, got, want)
+			}
+		}
+	}
+	return nil
+}
+
+// maxSampleDepth limits the depth of nested pointers, slices and maps of samples
+const maxSampleDepth = 3
+
+// populateSample sets every exported field of v to a non-zero value.
+func populateSample(v reflect.Value, depth int) {
+	if !v.CanSet() {
+		return
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		v.SetBool(true)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v.SetInt(int64(depth + 7))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		v.SetUint(uint64(depth + 7))
+	case reflect.Float32, reflect.Float64:
+		v.SetFloat(float64(depth) + 7.5)
+	case reflect.Complex64, reflect.Complex128:
+		v.SetComplex(complex(float64(depth)+7, 1))
+	case reflect.String:
+		v.SetString(fmt.Sprintf("sample%d", depth))
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			populateSample(v.Index(i), depth)
+		}
+	case reflect.Pointer:
+		if depth < maxSampleDepth {
+			v.Set(reflect.New(v.Type().Elem()))
+			populateSample(v.Elem(), depth+1)
+		}
+	case reflect.Slice:
+		if depth < maxSampleDepth {
+			v.Set(reflect.MakeSlice(v.Type(), 2, 2))
+			for i := 0; i < v.Len(); i++ {
+				populateSample(v.Index(i), depth+1)
+			}
+		}
+	case reflect.Map:
+		if depth < maxSampleDepth {
+			key := reflect.New(v.Type().Key()).Elem()
+			value := reflect.New(v.Type().Elem()).Elem()
+			populateSample(key, depth+1)
+			populateSample(value, depth+1)
+			v.Set(reflect.MakeMap(v.Type()))
+			v.SetMapIndex(key, value)
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			populateSample(v.Field(i), depth)
+		}
+	}
+}
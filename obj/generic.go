@@ -0,0 +1,44 @@
+package obj
+
+// MapTo maps src to a new value of type D.
+// Sample usage:
+//
+//	user, err := obj.MapTo[User](mapper, dto)
+//
+// On error, the partially mapped value is returned along with the error.
+func MapTo[D any](mapper *Mapper, src any) (D, error) {
+	var dst D
+	err := mapper.Map(src, &dst)
+	return dst, err
+}
+
+// MapSlice maps every element of src to a new slice of D. A nil src returns a nil slice.
+// Sample usage:
+//
+//	dtos, err := obj.MapSlice[UserEntity, UserDTO](mapper, users)
+//
+// On error, the partially mapped slice is returned along with the error.
+func MapSlice[S any, D any](mapper *Mapper, src []S) ([]D, error) {
+	if src == nil {
+		return nil, nil
+	}
+	dst := make([]D, 0, len(src))
+	err := mapper.Map(src, &dst)
+	return dst, err
+}
+
+// MapMap maps every value of src to a new map of D with the same keys. A nil src
+// returns a nil map.
+// Sample usage:
+//
+//	dtos, err := obj.MapMap[int, UserEntity, UserDTO](mapper, usersByID)
+//
+// On error, the partially mapped map is returned along with the error.
+func MapMap[K comparable, S any, D any](mapper *Mapper, src map[K]S) (map[K]D, error) {
+	if src == nil {
+		return nil, nil
+	}
+	dst := make(map[K]D, len(src))
+	err := mapper.Map(src, &dst)
+	return dst, err
+}
@@ -1,8 +1,10 @@
 package obj
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
+	"sync"
 )
 
 // ErrMismatchType returned when field of source can't be mapped to destination due to mismatched types.
@@ -10,25 +12,61 @@ var ErrMismatchType error = fmt.Errorf("type mismatch")
 var ErrInsufficientCapacity error = fmt.Errorf("insufficient capacity")
 var ErrNotAddresable error = fmt.Errorf("not addressable")
 
+// ErrOverflow returned when a value does not fit the destination when converting between kinds.
+// The returned error is an *OverflowError.
+var ErrOverflow error = fmt.Errorf("overflow")
+
+// ErrCycle returned when a source value references itself, unless the Mapper was
+// created with WithPreserveReferences.
+var ErrCycle error = fmt.Errorf("cycle detected")
+
 // AI generated code start
 var ErrFieldNotFound error = fmt.Errorf("field not found")
 
 // AI generated code end
 
+// Mapper maps values of one type to another. The mapping of every source and
+// destination struct type pair is compiled on first use and cached, so a Mapper
+// should be reused. A configured Mapper is safe for concurrent use.
 type Mapper struct {
-	cfg MapperConfig
+	cfg   MapperConfig
+	plans sync.Map // structMapKey -> *structPlan
 }
 
 // NewMapper creates a new instance of Mapper
-func NewMapper() *Mapper {
-	return &Mapper{
+func NewMapper(options ...MapperOption) *Mapper {
+	mapper := &Mapper{
 		cfg: MapperConfig{
 			fieldMaps: make(map[structMapKey]map[string]*FieldMapConfig),
 		},
 	}
+	for _, option := range options {
+		option(&mapper.cfg)
+	}
+	return mapper
 }
 
-// Map copies src field values to dst fields. Fields must have the same name.
+// Map copies src field values to dst fields. Fields must have the same name, or
+// names matching as configured with WithNameMatching, unless renamed with
+// [ConfigureFieldMaps] or a `map` struct tag on either struct, e.g.
+// `map:"SourceName"`, `map:"-"` to skip the field or `map:",omitempty"` to leave the
+// destination field untouched when the source value is zero. Either src or dst can
+// be a map with string keys instead of a struct, in which case keys are used as
+// field names. Fields of embedded structs are matched as promoted fields, and with
+// WithFlattening nested fields are matched to flattened names, e.g. Address.City
+// to AddressCity. Errors while mapping are returned as *MappingError, which records
+// the path of the failing field. Mapping stops at the first error unless the
+// Mapper was created with WithCollectErrors. A source value referencing itself,
+// e.g. a child with a pointer to its parent, fails with ErrCycle unless the Mapper
+// was created with WithPreserveReferences. Unexported fields are skipped unless
+// registered with [RegisterFieldAccessor], except that structs with unexported
+// fields, e.g. time.Time, are copied whole to the same type. Destination fields are
+// overwritten, unless configured otherwise with WithMergeStrategy. Slices are
+// appended to and maps are merged, unless configured otherwise with
+// WithCollectionStrategy.
+// Destination fields with no source are left untouched, unless the Mapper was
+// created with WithStrictMode. Fields are also read with getters, e.g. GetName(),
+// and written with setters, e.g. SetName(name), as configured with WithNamingStrategy.
 // Sample usage:
 //
 //	package main
@@ -72,16 +110,48 @@ func (m *Mapper) Map(src interface{}, dst interface{}) error {
 	if !dstValue.CanAddr() {
 		return ErrNotAddresable
 	}
-	return m.mapValue(srcValue, dstValue)
+	state := mapState{path: make([]pathSegment, 0, pathCapacity), collectErrors: m.cfg.collectErrors}
+	err := m.mapValue(&state, srcValue, dstValue)
+	if err != nil {
+		return err
+	}
+	return errors.Join(state.errs...)
+}
 
+// mapValue maps src to dst, wrapping errors in a *MappingError.
+func (m *Mapper) mapValue(state *mapState, src reflect.Value, dst reflect.Value) error {
+	err := m.doMapValue(state, src, dst)
+	if err != nil {
+		return state.fail(err, src.Type(), dst.Type())
+	}
+	return nil
 }
 
-func (m *Mapper) mapValue(src reflect.Value, dst reflect.Value) error {
+func (m *Mapper) doMapValue(state *mapState, src reflect.Value, dst reflect.Value) error {
 	if !src.IsValid() || !dst.IsValid() {
 		return nil
 	}
+	if convert, ok := m.converter(src, dst); ok {
+		dstValue, err := convert(src)
+		if err != nil {
+			return err
+		}
+		dst.Set(dstValue)
+		return nil
+	}
+	if m.cfg.clone && src.Type() == dst.Type() {
+		if cloned, err := m.cloneValue(state, src, dst); cloned {
+			return err
+		}
+	}
+	if src.Type().Kind() == reflect.Pointer && !src.IsNil() {
+		return m.mapPointer(state, src, dst)
+	}
 	if src.Type().Kind() == reflect.Pointer || src.Type().Kind() == reflect.Interface {
-		return m.mapValue(src.Elem(), dst)
+		return m.mapValue(state, src.Elem(), dst)
+	}
+	if m.cfg.conversions != 0 && src.Kind() != dst.Kind() && isConvertibleKind(src.Kind()) && isConvertibleKind(dst.Kind()) {
+		return m.convertValue(src, dst)
 	}
 
 	switch dst.Type().Kind() {
@@ -173,7 +243,9 @@ func (m *Mapper) mapValue(src reflect.Value, dst reflect.Value) error {
 
 		for i := 0; i < src.Len(); i++ {
 			dstItem := dst.Index(i)
-			err := m.mapValue(src.Index(i), dstItem)
+			state.pushIndex(i)
+			err := m.mapValue(state, src.Index(i), dstItem)
+			state.pop()
 			if err != nil {
 				return err
 			}
@@ -187,38 +259,61 @@ func (m *Mapper) mapValue(src reflect.Value, dst reflect.Value) error {
 			if !dst.Elem().CanAddr() { // for structs with interface fields, value in it is always not addressable
 				return ErrNotAddresable
 			}
-			return m.mapValue(src, dst.Elem())
+			return m.mapValue(state, src, dst.Elem())
 		}
 
 		newVal := reflect.New(src.Type())
-		err := m.mapValue(src, newVal)
+		err := m.mapValue(state, src, newVal)
 		if err != nil {
 			return err
 		}
 		dst.Set(newVal.Elem())
 	case reflect.Map:
+		if src.Type().Kind() == reflect.Struct && dst.Type().Key().Kind() == reflect.String {
+			return m.mapStructToMap(state, m.structPlan(src.Type(), dst.Type()), src, dst)
+		}
 		if src.Type().Kind() != reflect.Map {
 			return ErrMismatchType
 		}
 
+		strategy, _ := m.collectionStrategy(state.fieldMap())
+		if strategy == CollectionReplace {
+			if src.IsNil() {
+				dst.SetZero()
+				return nil
+			}
+			dst.Set(reflect.MakeMapWithSize(dst.Type(), src.Len()))
+		}
 		if dst.IsNil() {
 			dst.Set(reflect.MakeMap(dst.Type()))
 		}
+		if !state.enter(src) {
+			return ErrCycle
+		}
+		defer state.leave(src)
 
+		merge := (strategy == CollectionMergeByIndex || strategy == CollectionMergeByKey) &&
+			isMergeable(dst.Type().Elem())
 		iter := src.MapRange()
 		for iter.Next() {
 			// map key
 			srcKey := iter.Key()
 			dstKey := reflect.New(dst.Type().Key())
-			err := m.mapValue(srcKey, dstKey)
+			state.pushKey(srcKey)
+			err := m.mapValue(state, srcKey, dstKey)
 			if err != nil {
+				state.pop()
 				return err
 			}
 
 			// map value
 			srcVal := iter.Value()
 			dstVal := reflect.New(dst.Type().Elem())
-			err = m.mapValue(srcVal, dstVal)
+			if existing := dst.MapIndex(dstKey.Elem()); merge && existing.IsValid() {
+				dstVal.Elem().Set(existing)
+			}
+			err = m.mapValue(state, srcVal, dstVal)
+			state.pop()
 			if err != nil {
 				return err
 			}
@@ -230,20 +325,12 @@ func (m *Mapper) mapValue(src reflect.Value, dst reflect.Value) error {
 			new := reflect.New(dst.Type().Elem())
 			dst.Set(new)
 		}
-		return m.mapValue(src, dst.Elem())
+		return m.mapValue(state, src, dst.Elem())
 	case reflect.Slice:
 		if src.Type().Kind() != reflect.Array && src.Type().Kind() != reflect.Slice {
 			return ErrMismatchType
 		}
-		for i := 0; i < src.Len(); i++ {
-			dstElem := reflect.New(dst.Type().Elem())
-
-			err := m.mapValue(src.Index(i), dstElem.Elem())
-			if err != nil {
-				return err
-			}
-			dst.Set(reflect.Append(dst, dstElem.Elem()))
-		}
+		return m.mapSlice(state, src, dst)
 	case reflect.String:
 
 		if src.Type().Kind() != reflect.String {
@@ -251,14 +338,26 @@ func (m *Mapper) mapValue(src reflect.Value, dst reflect.Value) error {
 		}
 		dst.SetString(src.String())
 	case reflect.Struct:
-		if src.Type().Kind() != reflect.Struct {
+		if src.Type().Kind() != reflect.Struct && !isStringKeyedMap(src.Type()) {
 			return ErrMismatchType
 		}
-		err := m.mapStructFields(src, dst)
+		plan := m.structPlan(src.Type(), dst.Type())
+		if plan.whole && src.CanInterface() {
+			dst.Set(src)
+			return nil
+		}
+		if m.cfg.strict && plan.unmapped != nil {
+			return &UnmappedFieldsError{
+				UnmappedFields:  *plan.unmapped,
+				SourceType:      src.Type(),
+				DestinationType: dst.Type(),
+			}
+		}
+		err := m.mapStructFields(state, plan, src, dst)
 		if err != nil {
 			return err
 		}
-		err = m.mapStructSetters(src, dst)
+		err = m.mapStructSetters(state, plan, src, dst)
 		if err != nil {
 			return err
 		}
@@ -269,41 +368,58 @@ func (m *Mapper) mapValue(src reflect.Value, dst reflect.Value) error {
 	return nil
 }
 
-func (m *Mapper) mapStructFields(src reflect.Value, dst reflect.Value) error {
-	structMapKey := structMapKey{
-		source:      src.Type(),
-		destination: dst.Type(),
+// mapPointer maps the value src points to to dst. When references are preserved
+// and dst is a pointer, every pointer to the same source value is mapped to the
+// same destination value, otherwise a source value referencing itself is an ErrCycle.
+func (m *Mapper) mapPointer(state *mapState, src reflect.Value, dst reflect.Value) error {
+	if !m.cfg.preserveReferences || dst.Kind() != reflect.Pointer {
+		if !state.enter(src) {
+			return ErrCycle
+		}
+		err := m.mapValue(state, src.Elem(), dst)
+		state.leave(src)
+		return err
+	}
+
+	if mapped, ok := state.mapped(src, dst.Type()); ok {
+		dst.Set(mapped)
+		return nil
+	}
+	if dst.IsNil() {
+		dst.Set(reflect.New(dst.Type().Elem()))
 	}
-	fieldMaps := m.cfg.fieldMaps[structMapKey]
-	for i := 0; i < dst.NumField(); i++ {
-		dstField := dst.Field(i)
-		fieldMap := fieldMaps[dst.Type().Field(i).Name]
-		srcFieldName := dst.Type().Field(i).Name
-		if fieldMap != nil {
-			if len(fieldMap.Source) > 0 {
-				srcFieldName = fieldMap.Source
+	state.setMapped(src, dst)
+	return m.mapValue(state, src.Elem(), dst.Elem())
+}
+
+func (m *Mapper) mapStructFields(state *mapState, plan *structPlan, src reflect.Value, dst reflect.Value) error {
+	for _, field := range plan.fields {
+		dstField := dst.Field(field.index)
+		if field.nested != nil {
+			err := m.mapNestedFields(state, field, src, dstField)
+			if err != nil {
+				return err
 			}
+			continue
 		}
-		srcField := src.FieldByName(srcFieldName)
-		var err error
-		if !srcField.IsValid() {
-			// AI generated code block start
-			getterName := "Get" + srcFieldName
-			getterMethod := src.MethodByName(getterName)
-			if getterMethod.IsValid() && getterMethod.Type().NumIn() == 0 && getterMethod.Type().NumOut() == 1 {
-				srcField = getterMethod.Call(nil)[0]
-			}
-			// AI generated code block end
+
+		srcField := field.source.value(src)
+		if field.omitEmpty && (!srcField.IsValid() || srcField.IsZero()) {
+			continue
 		}
-		if fieldMap == nil || fieldMap.GetDestinationValue == nil {
-			err = m.mapValue(srcField, dstField)
+		if field.source.found() && skipMerge(m.mergeStrategy(field.fieldMap), srcField) {
+			continue
+		}
+
+		state.pushField(field.name)
+		state.setFieldMap(field.fieldMap)
+		var err error
+		if field.accessor != nil {
+			err = m.mapAccessor(state, field, srcField, dst)
 		} else {
-			dstValue, err := fieldMap.GetDestinationValue(srcField.Interface())
-			if err != nil {
-				return err
-			}
-			dstField.Set(reflect.ValueOf(dstValue))
+			err = m.mapField(state, field.fieldMap, srcField, dstField)
 		}
+		state.pop()
 		if err != nil {
 			return err
 		}
@@ -311,58 +427,244 @@ func (m *Mapper) mapStructFields(src reflect.Value, dst reflect.Value) error {
 	return nil
 }
 
-func (m *Mapper) mapStructSetters(src reflect.Value, dst reflect.Value) error {
-	// AI generated code block start
-	// Handle setter methods
-	dstType := dst.Addr().Type()
-	structMapKey := structMapKey{
-		source:      src.Type(),
-		destination: dst.Type(),
+// mapAccessor maps src to a field of dst written by a registered accessor.
+func (m *Mapper) mapAccessor(state *mapState, field fieldPlan, src reflect.Value, dst reflect.Value) error {
+	if !field.source.found() && (field.fieldMap == nil || field.fieldMap.GetDestinationValue == nil) {
+		return nil
+	}
+
+	value := reflect.New(field.accessor.fieldType).Elem()
+	if field.accessor.get != nil {
+		value.Set(field.accessor.get(dst))
 	}
-	fieldMaps := m.cfg.fieldMaps[structMapKey]
-	for i := 0; i < dstType.NumMethod(); i++ {
-		method := dstType.Method(i)
-		if method.Name[:3] == "Set" && method.Type.NumIn() == 2 && method.Type.NumOut() == 0 {
-			fieldName := method.Name[3:]
-			srcFieldName := fieldName
-			var fieldMap *FieldMapConfig
-			if fm, ok := fieldMaps[fieldName]; ok {
-				fieldMap = fm
-				if len(fieldMap.Source) > 0 {
-					srcFieldName = fieldMap.Source
-				}
+	err := m.mapField(state, field.fieldMap, src, value)
+	if err != nil {
+		return err
+	}
+	field.accessor.set(dst, value)
+	return nil
+}
+
+// mapNestedFields maps the fields of the struct dst, or the struct dst points to,
+// from the fields of src.
+func (m *Mapper) mapNestedFields(state *mapState, field fieldPlan, src reflect.Value, dst reflect.Value) error {
+	if dst.Kind() == reflect.Pointer {
+		if dst.IsNil() {
+			dst.Set(reflect.New(dst.Type().Elem()))
+		}
+		dst = dst.Elem()
+	}
+
+	if field.promoted {
+		return m.mapStructFields(state, field.nested, src, dst)
+	}
+	state.pushField(field.name)
+	err := m.mapStructFields(state, field.nested, src, dst)
+	state.pop()
+	return err
+}
+
+func (m *Mapper) mapStructSetters(state *mapState, plan *structPlan, src reflect.Value, dst reflect.Value) error {
+	for _, setter := range plan.setters {
+		state.pushField(setter.name)
+		err := m.mapSetter(state, setter, src, dst)
+		state.pop()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *Mapper) mapSetter(state *mapState, setter setterPlan, src reflect.Value, dst reflect.Value) error {
+	if !setter.source.found() {
+		return state.fail(ErrFieldNotFound, src.Type(), setter.paramType)
+	}
+	srcField := setter.source.value(src)
+	if !srcField.IsValid() || skipMerge(m.mergeStrategy(setter.fieldMap), srcField) {
+		return nil
+	}
+
+	paramValue := reflect.New(setter.paramType).Elem()
+	err := m.mapField(state, setter.fieldMap, srcField, paramValue)
+	if err != nil {
+		return err
+	}
+	results := dst.Addr().Method(setter.method).Call([]reflect.Value{paramValue})
+	if setter.fluent {
+		setFluentResult(dst, results[0])
+	}
+	return nil
+}
+
+// setFluentResult stores the destination returned by a fluent setter in dst.
+func setFluentResult(dst reflect.Value, result reflect.Value) {
+	if result.Kind() == reflect.Pointer {
+		if result.IsNil() {
+			return
+		}
+		result = result.Elem()
+	}
+	dst.Set(result)
+}
+
+// mapField maps src to the field dst, using the GetDestinationValue function of
+// fieldMap if configured.
+func (m *Mapper) mapField(state *mapState, fieldMap *FieldMapConfig, src reflect.Value, dst reflect.Value) error {
+	if fieldMap == nil || fieldMap.GetDestinationValue == nil {
+		if m.zeroNil(m.mergeStrategy(fieldMap), src, dst) {
+			dst.SetZero()
+			return nil
+		}
+		return m.mapValue(state, src, dst)
+	}
+
+	dstValue, err := fieldMap.GetDestinationValue(valueInterface(src))
+	if err != nil {
+		return state.fail(err, typeOf(src), dst.Type())
+	}
+	dst.Set(reflect.ValueOf(dstValue))
+	return nil
+}
+
+// typeOf returns the type of v, nil if v is invalid.
+func typeOf(v reflect.Value) reflect.Type {
+	if !v.IsValid() {
+		return nil
+	}
+	return v.Type()
+}
+
+func (m *Mapper) mapStructToMap(state *mapState, plan *structPlan, src reflect.Value, dst reflect.Value) error {
+	if dst.IsNil() {
+		dst.Set(reflect.MakeMap(dst.Type()))
+	}
+
+	elemType := dst.Type().Elem()
+	for _, field := range plan.fields {
+		srcField := field.source.value(src)
+		if !srcField.IsValid() || (field.omitEmpty && srcField.IsZero()) {
+			continue
+		}
+
+		dstElem := reflect.New(elemType).Elem()
+		state.pushField(field.name)
+		var err error
+		if isEmptyInterface(elemType) && (field.fieldMap == nil || field.fieldMap.GetDestinationValue == nil) {
+			err = m.mapLoose(state, srcField, dstElem)
+		} else {
+			err = m.mapField(state, field.fieldMap, srcField, dstElem)
+		}
+		state.pop()
+		if err != nil {
+			return err
+		}
+		dst.SetMapIndex(reflect.ValueOf(field.name).Convert(dst.Type().Key()), dstElem)
+	}
+	return nil
+}
+
+var looseMapType = reflect.TypeOf(map[string]any{})
+var looseSliceType = reflect.TypeOf([]any{})
+
+// mapLoose sets dst, an empty interface, to src with structs and maps with string
+// keys flattened into map[string]any, and slices and arrays into []any.
+func (m *Mapper) mapLoose(state *mapState, src reflect.Value, dst reflect.Value) error {
+	if src.Kind() == reflect.Pointer || src.Kind() == reflect.Interface {
+		if src.IsNil() {
+			return nil
+		}
+		if src.Kind() == reflect.Pointer {
+			if !state.enter(src) {
+				return ErrCycle
 			}
-			srcField := src.FieldByName(srcFieldName)
-			if !srcField.IsValid() {
-				getterName := "Get" + srcFieldName
-				getterMethod := src.MethodByName(getterName)
-				if getterMethod.IsValid() && getterMethod.Type().NumIn() == 0 && getterMethod.Type().NumOut() == 1 {
-					srcField = getterMethod.Call(nil)[0]
-				} else {
-					return ErrFieldNotFound
-				}
+			defer state.leave(src)
+		}
+		return m.mapLoose(state, src.Elem(), dst)
+	}
+
+	switch {
+	case src.Kind() == reflect.Struct && hasExportedFields(src.Type()):
+		flattened := reflect.New(looseMapType).Elem()
+		err := m.mapValue(state, src, flattened)
+		if err != nil {
+			return err
+		}
+		dst.Set(flattened)
+	case isStringKeyedMap(src.Type()):
+		if src.IsNil() {
+			return nil
+		}
+		if !state.enter(src) {
+			return ErrCycle
+		}
+		defer state.leave(src)
+		flattened := reflect.MakeMapWithSize(looseMapType, src.Len())
+		iter := src.MapRange()
+		for iter.Next() {
+			value := reflect.New(looseMapType.Elem()).Elem()
+			state.pushKey(iter.Key())
+			err := m.mapLoose(state, iter.Value(), value)
+			state.pop()
+			if err != nil {
+				return err
 			}
-			if srcField.IsValid() {
-				var paramValue reflect.Value
-				if fieldMap == nil || fieldMap.GetDestinationValue == nil {
-					paramType := method.Type.In(1)
-					paramValue = reflect.New(paramType).Elem()
-					err := m.mapValue(srcField, paramValue)
-					if err != nil {
-						return err
-					}
-				} else {
-					dstValue, err := fieldMap.GetDestinationValue(srcField.Interface())
-					if err != nil {
-						return err
-					}
-					paramValue = reflect.ValueOf(dstValue)
-				}
-				setterMethod := dst.Addr().MethodByName(method.Name)
-				setterMethod.Call([]reflect.Value{paramValue})
+			flattened.SetMapIndex(reflect.ValueOf(iter.Key().String()), value)
+		}
+		dst.Set(flattened)
+	case src.Kind() == reflect.Slice || src.Kind() == reflect.Array:
+		if src.Kind() == reflect.Slice && src.IsNil() {
+			return nil
+		}
+		flattened := reflect.MakeSlice(looseSliceType, src.Len(), src.Len())
+		for i := 0; i < src.Len(); i++ {
+			state.pushIndex(i)
+			err := m.mapLoose(state, src.Index(i), flattened.Index(i))
+			state.pop()
+			if err != nil {
+				return err
 			}
 		}
+		dst.Set(flattened)
+	default:
+		dst.Set(src)
 	}
-	// AI generated code block end
 	return nil
 }
+
+func isStringKeyedMap(t reflect.Type) bool {
+	return t.Kind() == reflect.Map && t.Key().Kind() == reflect.String
+}
+
+func isEmptyInterface(t reflect.Type) bool {
+	return t.Kind() == reflect.Interface && t.NumMethod() == 0
+}
+
+func hasExportedFields(t reflect.Type) bool {
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).IsExported() {
+			return true
+		}
+	}
+	return false
+}
+
+// converter returns the converter registered for the types of src and dst.
+func (m *Mapper) converter(src reflect.Value, dst reflect.Value) (converter, bool) {
+	if len(m.cfg.converters) == 0 || !src.CanInterface() {
+		return nil, false
+	}
+	convert, ok := m.cfg.converters[structMapKey{
+		source:      src.Type(),
+		destination: dst.Type(),
+	}]
+	return convert, ok
+}
+
+// valueInterface returns the value held by v, nil if v is invalid.
+func valueInterface(v reflect.Value) any {
+	if !v.IsValid() {
+		return nil
+	}
+	return v.Interface()
+}
@@ -11,6 +11,16 @@ type FieldMapConfig struct {
 	Source              string
 	Destination         string
 	GetDestinationValue func(source any) (any, error)
+
+	// MergeStrategy overrides the merge strategy of the Mapper for the field
+	MergeStrategy MergeStrategy
+
+	// CollectionStrategy overrides the collection strategy of the Mapper for the
+	// field, a slice or map
+	CollectionStrategy CollectionStrategy
+
+	// CollectionKey overrides the key field of the Mapper for CollectionMergeByKey
+	CollectionKey string
 }
 
 type structMapKey struct {
@@ -18,19 +28,155 @@ type structMapKey struct {
 	destination reflect.Type
 }
 
+// MapperConfig contains the configuration of a Mapper
 type MapperConfig struct {
-	fieldMaps map[structMapKey]map[string]*FieldMapConfig
+	fieldMaps     map[structMapKey]map[string]*FieldMapConfig
+	converters    map[structMapKey]converter
+	conversions   Conversion
+	collectErrors bool
+	flatten       bool
+	mergeStrategy MergeStrategy
+
+	collectionStrategy CollectionStrategy
+	collectionKey      string
+
+	strict          bool
+	allowedUnmapped map[structMapKey]UnmappedFields
+
+	preserveReferences bool
+
+	accessors map[fieldKey]*fieldAccessor
+
+	// namingStrategy is nil if not configured, see DefaultNamingStrategy
+	namingStrategy *NamingStrategy
+
+	// nameMatching is nil if names must match exactly
+	nameMatching NameMatching
+
+	// clone indicates that values are deep copied to the same type, see Clone
+	clone bool
+}
+
+// fieldKey identifies a field of a struct type by name.
+type fieldKey struct {
+	structType reflect.Type
+	name       string
+}
+
+// fieldAccessor reads and writes a field, see RegisterFieldAccessor. Either get
+// or set is nil if not provided.
+type fieldAccessor struct {
+	fieldType reflect.Type
+	get       func(src reflect.Value) reflect.Value
+	set       func(dst reflect.Value, value reflect.Value)
+}
+
+// converter converts a value of one type to another, see RegisterConverter.
+type converter func(src reflect.Value) (reflect.Value, error)
+
+// MapperOption configures a Mapper created by NewMapper
+type MapperOption func(cfg *MapperConfig)
+
+// WithConversion allows values to be converted between different kinds, e.g. int32
+// to int64. Lossless widening is always allowed; conversions can add
+// ConversionNarrowing and ConversionString.
+func WithConversion(conversions Conversion) MapperOption {
+	return func(cfg *MapperConfig) {
+		cfg.conversions = conversions | ConversionWidening
+	}
+}
+
+// WithFlattening maps fields of nested structs to and from flattened fields named
+// after the path of the nested field, e.g. Address.City to and from AddressCity.
+func WithFlattening() MapperOption {
+	return func(cfg *MapperConfig) {
+		cfg.flatten = true
+	}
+}
+
+func isFieldMappable(t reflect.Type) bool {
+	return t != nil && (t.Kind() == reflect.Struct || isStringKeyedMap(t))
+}
+
+// WithCollectErrors makes Map continue mapping the remaining fields after a field
+// fails. The errors of every failing field are returned joined with [errors.Join].
+func WithCollectErrors() MapperOption {
+	return func(cfg *MapperConfig) {
+		cfg.collectErrors = true
+	}
+}
+
+// WithMergeStrategy sets when source fields overwrite destination fields, e.g.
+// MergeSkipNilPointer to apply a patch request with pointer fields to an existing
+// value. The strategy can be overridden per field with [FieldMapConfig].
+func WithMergeStrategy(strategy MergeStrategy) MapperOption {
+	return func(cfg *MapperConfig) {
+		cfg.mergeStrategy = strategy
+	}
+}
+
+// WithCollectionStrategy sets how slices and maps are mapped to destinations which
+// already have elements, e.g. CollectionMergeByKey to update the children of an
+// existing entity instead of duplicating them. keyField is the field identifying
+// elements for CollectionMergeByKey, "ID" if not provided. The strategy can be
+// overridden per field with [FieldMapConfig].
+func WithCollectionStrategy(strategy CollectionStrategy, keyField ...string) MapperOption {
+	return func(cfg *MapperConfig) {
+		cfg.collectionStrategy = strategy
+		if len(keyField) > 0 {
+			cfg.collectionKey = keyField[0]
+		}
+	}
 }
 
-// ConfigureFieldMaps allows overriding of how fields are mapped for sourceT and destinationT
+// WithStrictMode makes Map fail with an *UnmappedFieldsError when mapping structs
+// with destination fields with no source or source fields not mapped to any
+// destination field, unless allowed with [AllowUnmappedFields]. Mapping structs
+// to and from maps is not checked.
+func WithStrictMode() MapperOption {
+	return func(cfg *MapperConfig) {
+		cfg.strict = true
+	}
+}
+
+// WithPreserveReferences makes Map preserve the shape of the source graph: pointers
+// to the same source value are mapped to pointers to the same destination value,
+// so shared and cyclic references, e.g. a child with a pointer to its parent, can
+// be mapped.
+func WithPreserveReferences() MapperOption {
+	return func(cfg *MapperConfig) {
+		cfg.preserveReferences = true
+	}
+}
+
+// WithNamingStrategy sets the getters and setters mapped as if they were fields,
+// e.g. to map getters named after their field or fluent WithName setters. The zero
+// NamingStrategy disables mapping with methods.
+func WithNamingStrategy(strategy NamingStrategy) MapperOption {
+	return func(cfg *MapperConfig) {
+		cfg.namingStrategy = &strategy
+	}
+}
+
+// WithNameMatching sets how names of source fields, getters and map keys match the
+// names of destination fields and setters, e.g. MatchNormalized to map the key
+// user_id to the field UserID.
+func WithNameMatching(matching NameMatching) MapperOption {
+	return func(cfg *MapperConfig) {
+		cfg.nameMatching = matching
+	}
+}
+
+// ConfigureFieldMaps allows overriding of how fields are mapped for sourceT and destinationT.
+// Either can be a map with string keys, in which case Source or Destination is a map key.
 func ConfigureFieldMaps[sourceT any, destinationT any](mapper *Mapper,
 	fieldMapConfigs ...FieldMapConfig) error {
 	var zeroSource sourceT
 	var zeroDestination destinationT
 	sourceType := reflect.TypeOf(zeroSource)
 	destinationType := reflect.TypeOf(zeroDestination)
-	if sourceType.Kind() != reflect.Struct || destinationType.Kind() != reflect.Struct {
-		return fmt.Errorf("sourceT and destinationT must be structs")
+	if !isFieldMappable(sourceType) || !isFieldMappable(destinationType) {
+		return fmt.Errorf("sourceT and destinationT must be structs or maps with string keys")
 	}
 
 	structKey := structMapKey{
@@ -51,5 +197,84 @@ func ConfigureFieldMaps[sourceT any, destinationT any](mapper *Mapper,
 		fieldMap[cfg.Destination] = &cfg
 	}
 	mapper.cfg.fieldMaps[structKey] = fieldMap
+	mapper.resetPlans()
+	return nil
+}
+
+// RegisterConverter registers a function converting sourceT to destinationT. The
+// converter is used wherever a value of exactly sourceT is mapped to exactly
+// destinationT, including fields of nested structs and elements of slices, arrays
+// and maps. [FieldMapConfig.GetDestinationValue] takes precedence over converters.
+// Registering a converter for the same types replaces the previous one.
+// Sample usage:
+//
+//	err := obj.RegisterConverter(mapper, func(t time.Time) (string, error) {
+//		return t.Format(time.RFC3339), nil
+//	})
+func RegisterConverter[sourceT any, destinationT any](mapper *Mapper,
+	convert func(source sourceT) (destinationT, error)) error {
+	if convert == nil {
+		return fmt.Errorf("convert function must be provided")
+	}
+
+	key := structMapKey{
+		source:      reflect.TypeFor[sourceT](),
+		destination: reflect.TypeFor[destinationT](),
+	}
+	if mapper.cfg.converters == nil {
+		mapper.cfg.converters = make(map[structMapKey]converter)
+	}
+	mapper.cfg.converters[key] = func(src reflect.Value) (reflect.Value, error) {
+		source, _ := src.Interface().(sourceT) // the zero value of an interface sourceT if nil
+		dst, err := convert(source)
+		return reflect.ValueOf(&dst).Elem(), err
+	}
+	return nil
+}
+
+// RegisterFieldAccessor allows the field named name of structT to be mapped through
+// get when structT is the source and set when it is the destination. Unexported
+// fields are skipped by Map unless registered, as reflection can't read or write
+// them. Either get or set can be nil. Registering an accessor for the same field
+// replaces the previous one.
+// Sample usage:
+//
+//	err := obj.RegisterFieldAccessor(mapper, "password",
+//		func(u User) string { return u.password },
+//		func(u *User, password string) { u.password = password })
+func RegisterFieldAccessor[structT any, fieldT any](mapper *Mapper, name string,
+	get func(source structT) fieldT, set func(destination *structT, value fieldT)) error {
+	structType := reflect.TypeFor[structT]()
+	if structType.Kind() != reflect.Struct {
+		return fmt.Errorf("structT must be a struct")
+	}
+	field, ok := structType.FieldByName(name)
+	if !ok || field.Type != reflect.TypeFor[fieldT]() {
+		return fmt.Errorf("%s has no field %s of type %s", structType, name, reflect.TypeFor[fieldT]())
+	}
+	if get == nil && set == nil {
+		return fmt.Errorf("get or set function must be provided")
+	}
+
+	accessor := &fieldAccessor{fieldType: field.Type}
+	if get != nil {
+		accessor.get = func(src reflect.Value) reflect.Value {
+			if !src.CanInterface() {
+				return reflect.Value{}
+			}
+			value := get(src.Interface().(structT))
+			return reflect.ValueOf(&value).Elem()
+		}
+	}
+	if set != nil {
+		accessor.set = func(dst reflect.Value, value reflect.Value) {
+			set(dst.Addr().Interface().(*structT), value.Interface().(fieldT))
+		}
+	}
+	if mapper.cfg.accessors == nil {
+		mapper.cfg.accessors = make(map[fieldKey]*fieldAccessor)
+	}
+	mapper.cfg.accessors[fieldKey{structType, name}] = accessor
+	mapper.resetPlans()
 	return nil
 }
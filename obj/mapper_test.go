@@ -1,10 +1,19 @@
 package obj
 
 import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"math"
+	"math/big"
+	"os"
 	"reflect"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -143,7 +152,7 @@ func TestMapArrayDst(t *testing.T) {
 			mapper := NewMapper()
 			err := mapper.Map(test.src, &test.dst)
 			if err != nil || test.err != nil {
-				assert.Equal(t, test.err, err, "Error not equal")
+				assert.ErrorIs(t, err, test.err, "Error not equal")
 				return
 			}
 			srcV := reflect.ValueOf(test.src)
@@ -274,7 +283,7 @@ func TestMapMismatchType(t *testing.T) {
 		//t.Run(test.name, func(t *testing.T) {
 		mapper := NewMapper()
 		err := mapper.Map(src, test.dst)
-		assert.Equal(t, ErrMismatchType, err)
+		assert.ErrorIs(t, err, ErrMismatchType)
 		//})
 	}
 }
@@ -309,7 +318,7 @@ func TestMapSliceDst(t *testing.T) {
 			mapper := NewMapper()
 			err := mapper.Map(test.src, &test.dst)
 			if err != nil || test.err != nil {
-				assert.Equal(t, test.err, err, "Error not equal")
+				assert.ErrorIs(t, err, test.err, "Error not equal")
 				return
 			}
 			srcV := reflect.ValueOf(test.src)
@@ -357,7 +366,7 @@ func TestMapMapDst(t *testing.T) {
 			mapper := NewMapper()
 			err := mapper.Map(test.src, &test.dst)
 			if err != nil || test.err != nil {
-				assert.Equal(t, test.err, err, "Error not equal")
+				assert.ErrorIs(t, err, test.err, "Error not equal")
 				return
 			}
 			assert.Equal(t, test.src, test.dst)
@@ -454,7 +463,7 @@ func TestMapWithFieldMaps(t *testing.T) {
 
 			err = mapper.Map(test.src, test.dst)
 			if test.mapErr != nil || err != nil {
-				assert.Equal(t, test.mapErr, err)
+				assert.Equal(t, test.mapErr, errors.Unwrap(err))
 				return
 			}
 			assert.Equal(t, test.expected, test.dst)
@@ -469,7 +478,7 @@ func TestConfigureFieldMapsSourceNotStruct(t *testing.T) {
 		Destination: "Int",
 	}
 	err := ConfigureFieldMaps[int, testAllTypes](mapper, cfg)
-	assert.Equal(t, fmt.Errorf("sourceT and destinationT must be structs"), err)
+	assert.Equal(t, fmt.Errorf("sourceT and destinationT must be structs or maps with string keys"), err)
 }
 
 func TestConfigureFieldMapsDestinationNotStruct(t *testing.T) {
@@ -479,7 +488,7 @@ func TestConfigureFieldMapsDestinationNotStruct(t *testing.T) {
 		Destination: "Int",
 	}
 	err := ConfigureFieldMaps[testAllTypes, int](mapper, cfg)
-	assert.Equal(t, fmt.Errorf("sourceT and destinationT must be structs"), err)
+	assert.Equal(t, fmt.Errorf("sourceT and destinationT must be structs or maps with string keys"), err)
 }
 
 func TestConfigureFieldMapsDestinationFieldEmpty(t *testing.T) {
@@ -572,7 +581,7 @@ func TestMapWithDifferentSetter(t *testing.T) {
 	mapper := NewMapper()
 	err := mapper.Map(dto, &user)
 
-	assert.Equal(t, ErrMismatchType, err, "Error not equal")
+	assert.ErrorIs(t, err, ErrMismatchType, "Error not equal")
 	assert.Equal(t, 0, user.withSetterID, "ID not equal")
 	assert.Equal(t, "", user.withSetterName, "Name not equal")
 }
@@ -700,7 +709,7 @@ func TestMapWithInterfaceField(t *testing.T) {
 		t.Run(test.name, func(t *testing.T) {
 			mapper := NewMapper()
 			err := mapper.Map(test.src, &test.dst)
-			assert.Equal(t, test.err, err, "Error not equal")
+			assert.ErrorIs(t, err, test.err, "Error not equal")
 			assert.Equal(t, test.expected, test.dst.Data, "Data not equal")
 		})
 	}
@@ -763,7 +772,7 @@ func TestMapWithNestedInterfaceField(t *testing.T) {
 		t.Run(test.name, func(t *testing.T) {
 			mapper := NewMapper()
 			err := mapper.Map(test.src, &test.dst)
-			assert.Equal(t, test.err, err, "Error not equal")
+			assert.ErrorIs(t, err, test.err, "Error not equal")
 			assert.Equal(t, test.expected, test.dst.Inner, "Inner not equal")
 		})
 	}
@@ -800,7 +809,7 @@ func TestMapWithInitializedInterfaceField(t *testing.T) {
 	mapper := NewMapper()
 	err := mapper.Map(src, &dst)
 
-	assert.Equal(t, ErrNotAddresable, err, "Error not equal")
+	assert.ErrorIs(t, err, ErrNotAddresable, "Error not equal")
 	assert.NotEqual(t, src.Data, dst.Data, "Data equal")
 }
 
@@ -842,3 +851,2189 @@ func TestMapWithNestedStructAndCustomFunction(t *testing.T) {
 }
 
 // AI generated code end
+
+func TestMapWithTags(t *testing.T) {
+	type Source struct {
+		ID       int
+		FullName string
+		Nickname string
+		Password string
+		Email    string `map:"EmailAddress"`
+		Phone    string `map:"-"`
+	}
+	type Destination struct {
+		ID           int
+		Name         string `map:"FullName"`
+		Nickname     string `map:",omitempty"`
+		Password     string `map:"-"`
+		EmailAddress string
+		Email        string
+		Phone        string
+	}
+
+	tests := []struct {
+		name     string
+		src      Source
+		dst      Destination
+		expected Destination
+	}{
+		{
+			name: "Rename, skip and source tags",
+			src: Source{
+				ID:       1,
+				FullName: "John Doe",
+				Nickname: "Johnny",
+				Password: "secret",
+				Email:    "john@example.com",
+				Phone:    "555-0100",
+			},
+			expected: Destination{
+				ID:           1,
+				Name:         "John Doe",
+				Nickname:     "Johnny",
+				EmailAddress: "john@example.com",
+			},
+		},
+		{
+			name: "Omit empty keeps destination value",
+			src:  Source{ID: 1},
+			dst:  Destination{Nickname: "Johnny", Password: "secret"},
+			expected: Destination{
+				ID:       1,
+				Nickname: "Johnny",
+				Password: "secret",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mapper := NewMapper()
+			err := mapper.Map(test.src, &test.dst)
+			assert.Nil(t, err, "Map returned an error")
+			assert.Equal(t, test.expected, test.dst)
+		})
+	}
+}
+
+func TestMapWithTagsAndFieldMaps(t *testing.T) {
+	type Source struct {
+		FullName string
+		Alias    string
+	}
+	type Destination struct {
+		Name string `map:"FullName"`
+	}
+
+	mapper := NewMapper()
+	err := ConfigureFieldMaps[Source, Destination](mapper, FieldMapConfig{
+		Source:      "Alias",
+		Destination: "Name",
+	})
+	assert.Nil(t, err, "ConfigureFieldMaps returned an error")
+
+	dst := Destination{}
+	err = mapper.Map(Source{FullName: "John Doe", Alias: "JD"}, &dst)
+	assert.Nil(t, err, "Map returned an error")
+	assert.Equal(t, "JD", dst.Name, "Field map did not take precedence over tag")
+}
+
+func TestMapConcurrent(t *testing.T) {
+	mapper := NewMapper()
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			dst := benchUserDTO{}
+			err := mapper.Map(benchUser, &dst)
+			assert.Nil(t, err, "Map returned an error")
+			assert.Equal(t, "John Doe", dst.FullName, "FullName not equal")
+			assert.Equal(t, benchUser.Address, dst.Address, "Address not equal")
+		}()
+	}
+	wg.Wait()
+}
+
+func TestConfigureFieldMapsAfterMap(t *testing.T) {
+	mapper := NewMapper()
+	dst := benchUserDTO{}
+	err := mapper.Map(benchUser, &dst)
+	assert.Nil(t, err, "Map returned an error")
+
+	err = ConfigureFieldMaps[benchUserEntity, benchUserDTO](mapper, FieldMapConfig{
+		Source:      "LastName",
+		Destination: "FirstName",
+	})
+	assert.Nil(t, err, "ConfigureFieldMaps returned an error")
+
+	dst = benchUserDTO{}
+	err = mapper.Map(benchUser, &dst)
+	assert.Nil(t, err, "Map returned an error")
+	assert.Equal(t, "Doe", dst.FirstName, "Cached plan not discarded after configuration")
+}
+
+type benchAddress struct {
+	Street string
+	City   string
+	Zip    string
+}
+
+type benchUserEntity struct {
+	ID        int64
+	FirstName string
+	LastName  string
+	Email     string
+	Age       int
+	Active    bool
+	Tags      []string
+	Address   benchAddress
+}
+
+func (u benchUserEntity) GetFullName() string {
+	return u.FirstName + " " + u.LastName
+}
+
+type benchUserDTO struct {
+	ID        int64
+	FirstName string
+	LastName  string
+	FullName  string
+	Email     string
+	Age       int
+	Active    bool
+	Tags      []string
+	Address   benchAddress
+}
+
+var benchUser = benchUserEntity{
+	ID:        1,
+	FirstName: "John",
+	LastName:  "Doe",
+	Email:     "john@example.com",
+	Age:       30,
+	Active:    true,
+	Tags:      []string{"admin", "user"},
+	Address:   benchAddress{Street: "1 Main St", City: "Springfield", Zip: "12345"},
+}
+
+// BenchmarkMap compares reusing a Mapper, which reuses the cached plans, with
+// creating a Mapper for every call, which measures the cost of compiling the plans.
+// Neither runs the mapper from before plans were cached, which looked up fields and
+// methods by name on every call.
+func BenchmarkMap(b *testing.B) {
+	b.Run("Cached", func(b *testing.B) {
+		mapper := NewMapper()
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			dst := benchUserDTO{}
+			if err := mapper.Map(benchUser, &dst); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("CompilePlans", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			dst := benchUserDTO{}
+			if err := NewMapper().Map(benchUser, &dst); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func TestMapWithConversion(t *testing.T) {
+	type Numbers struct {
+		Int8    int8
+		Int32   int32
+		Uint16  uint16
+		Float32 float32
+		Int     int
+		Float64 float64
+		String  string
+		Bool    bool
+	}
+
+	tests := []struct {
+		name        string
+		conversions Conversion
+		src         any
+		dst         any
+		expected    any
+		err         error
+	}{
+		{
+			name: "Widening",
+			src: struct {
+				Int8, Int32 int8
+				Uint16      uint8
+				Float32     int16
+			}{1, 2, 3, 4},
+			dst:      &Numbers{},
+			expected: &Numbers{Int8: 1, Int32: 2, Uint16: 3, Float32: 4},
+		},
+		{
+			name: "Widening int to float32 is lossy",
+			src:  struct{ Float32 int }{1},
+			dst:  &Numbers{},
+			err:  ErrMismatchType,
+		},
+		{
+			name: "Narrowing not allowed",
+			src:  struct{ Int8 int64 }{1},
+			dst:  &Numbers{},
+			err:  ErrMismatchType,
+		},
+		{
+			name:        "Narrowing",
+			conversions: ConversionNarrowing,
+			src:         struct{ Int8, Uint16, Int, Float32 float64 }{-128, 65535, 42, 1.5},
+			dst:         &Numbers{},
+			expected:    &Numbers{Int8: -128, Uint16: 65535, Int: 42, Float32: 1.5},
+		},
+		{
+			name:        "Narrowing overflow",
+			conversions: ConversionNarrowing,
+			src:         struct{ Int8 int }{128},
+			dst:         &Numbers{},
+			err:         &OverflowError{Field: "Int8", Value: 128, Type: reflect.TypeOf(int8(0))},
+		},
+		{
+			name:        "Narrowing exact integer to float",
+			conversions: ConversionNarrowing,
+			src: struct {
+				Float64 int64
+				Float32 uint64
+			}{1 << 53, 1 << 24},
+			dst:      &Numbers{},
+			expected: &Numbers{Float64: 1 << 53, Float32: 1 << 24},
+		},
+		{
+			name:        "Narrowing inexact integer to float",
+			conversions: ConversionNarrowing,
+			src:         struct{ Float64 int64 }{1<<53 + 1},
+			dst:         &Numbers{},
+			err:         &OverflowError{Field: "Float64", Value: int64(1<<53 + 1), Type: reflect.TypeOf(float64(0))},
+		},
+		{
+			name:        "Narrowing inexact integer to float32",
+			conversions: ConversionNarrowing,
+			src:         struct{ Float32 int32 }{1<<24 + 1},
+			dst:         &Numbers{},
+			err:         &OverflowError{Field: "Float32", Value: int32(1<<24 + 1), Type: reflect.TypeOf(float32(0))},
+		},
+		{
+			name:        "Narrowing negative to unsigned",
+			conversions: ConversionNarrowing,
+			src:         struct{ Uint16 int }{-1},
+			dst:         &Numbers{},
+			err:         &OverflowError{Field: "Uint16", Value: -1, Type: reflect.TypeOf(uint16(0))},
+		},
+		{
+			name:        "Narrowing float with fraction to int",
+			conversions: ConversionNarrowing,
+			src:         struct{ Int float64 }{1.5},
+			dst:         &Numbers{},
+			err:         &OverflowError{Field: "Int", Value: 1.5, Type: reflect.TypeOf(0)},
+		},
+		{
+			name: "String not allowed",
+			src:  struct{ String int }{1},
+			dst:  &Numbers{},
+			err:  ErrMismatchType,
+		},
+		{
+			name:        "To string",
+			conversions: ConversionString,
+			src:         struct{ String float64 }{1.5},
+			dst:         &Numbers{},
+			expected:    &Numbers{String: "1.5"},
+		},
+		{
+			name:        "From string",
+			conversions: ConversionString,
+			src:         struct{ Int, Float64, Bool string }{"42", "1.5", "true"},
+			dst:         &Numbers{},
+			expected:    &Numbers{Int: 42, Float64: 1.5, Bool: true},
+		},
+		{
+			name:        "From string overflow",
+			conversions: ConversionString,
+			src:         struct{ Int8 string }{"300"},
+			dst:         &Numbers{},
+			err:         &OverflowError{Field: "Int8", Value: "300", Type: reflect.TypeOf(int8(0))},
+		},
+		{
+			name:        "From invalid string",
+			conversions: ConversionString,
+			src:         struct{ Int string }{"abc"},
+			dst:         &Numbers{},
+			err:         ErrMismatchType,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mapper := NewMapper(WithConversion(test.conversions))
+			err := mapper.Map(test.src, test.dst)
+			if test.err != nil || err != nil {
+				var overflowErr *OverflowError
+				if _, ok := test.err.(*OverflowError); ok {
+					assert.ErrorAs(t, err, &overflowErr)
+					assert.Equal(t, test.err, overflowErr, "Overflow error not equal")
+					assert.ErrorIs(t, err, ErrOverflow)
+				} else {
+					assert.ErrorIs(t, err, test.err)
+				}
+				return
+			}
+			assert.Equal(t, test.expected, test.dst)
+		})
+	}
+}
+
+func TestMapWithoutConversion(t *testing.T) {
+	mapper := NewMapper()
+	dst := struct{ Int64 int64 }{}
+	err := mapper.Map(struct{ Int64 int32 }{1}, &dst)
+	assert.ErrorIs(t, err, ErrMismatchType)
+}
+
+func TestMapMappingError(t *testing.T) {
+	type SourceAddress struct {
+		Zip int
+	}
+	type SourceOrder struct {
+		Address SourceAddress
+	}
+	type Source struct {
+		Orders []SourceOrder
+		Items  map[string]SourceAddress
+	}
+	type DestinationAddress struct {
+		Zip string
+	}
+	type DestinationOrder struct {
+		Address DestinationAddress
+	}
+	type OrdersDestination struct {
+		Orders []DestinationOrder
+	}
+	type ItemsDestination struct {
+		Items map[string]DestinationAddress
+	}
+
+	tests := []struct {
+		name    string
+		src     any
+		dst     any
+		path    string
+		message string
+	}{
+		{
+			name:    "Slice of nested structs",
+			src:     Source{Orders: []SourceOrder{{}, {Address: SourceAddress{Zip: 12345}}}},
+			dst:     &OrdersDestination{},
+			path:    "Orders[0].Address.Zip",
+			message: "map Orders[0].Address.Zip (int to string): type mismatch",
+		},
+		{
+			name:    "Map value",
+			src:     Source{Items: map[string]SourceAddress{"home": {Zip: 12345}}},
+			dst:     &ItemsDestination{},
+			path:    "Items[home].Zip",
+			message: "map Items[home].Zip (int to string): type mismatch",
+		},
+		{
+			name:    "Root value",
+			src:     1,
+			dst:     &ItemsDestination{},
+			path:    "",
+			message: "map int to obj.ItemsDestination: type mismatch",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mapper := NewMapper()
+			err := mapper.Map(test.src, test.dst)
+
+			var mappingErr *MappingError
+			assert.ErrorAs(t, err, &mappingErr)
+			assert.ErrorIs(t, err, ErrMismatchType)
+			assert.Equal(t, test.path, mappingErr.Path, "Path not equal")
+			assert.Equal(t, test.message, err.Error(), "Message not equal")
+		})
+	}
+}
+
+func TestMapMappingErrorSetter(t *testing.T) {
+	mapper := NewMapper()
+	user := testUserWithSetter{}
+	err := mapper.Map(struct{ ID int }{1}, &user)
+
+	var mappingErr *MappingError
+	assert.ErrorAs(t, err, &mappingErr)
+	assert.ErrorIs(t, err, ErrFieldNotFound)
+	assert.Equal(t, "Name", mappingErr.Path, "Path not equal")
+	assert.Equal(t, reflect.TypeOf(""), mappingErr.DestinationType, "DestinationType not equal")
+}
+
+func TestMapWithCollectErrors(t *testing.T) {
+	type Source struct {
+		Name    int
+		Age     int
+		Email   string
+		Scores  []string
+		Country int
+	}
+	type Destination struct {
+		Name    string
+		Age     int
+		Email   string
+		Scores  []int
+		Country string
+	}
+
+	src := Source{Name: 1, Age: 30, Email: "john@example.com", Scores: []string{"a", "b"}, Country: 2}
+	dst := Destination{}
+	mapper := NewMapper(WithCollectErrors())
+	err := mapper.Map(src, &dst)
+
+	assert.ErrorIs(t, err, ErrMismatchType)
+	assert.Equal(t, Destination{Age: 30, Email: "john@example.com", Scores: []int{0, 0}}, dst, "Valid fields not mapped")
+
+	var paths []string
+	for _, fieldErr := range err.(interface{ Unwrap() []error }).Unwrap() {
+		var mappingErr *MappingError
+		assert.ErrorAs(t, fieldErr, &mappingErr)
+		paths = append(paths, mappingErr.Path)
+	}
+	assert.Equal(t, []string{"Name", "Scores[0]", "Scores[1]", "Country"}, paths, "Failing paths not equal")
+}
+
+func TestMapWithoutCollectErrors(t *testing.T) {
+	type Source struct {
+		Name int
+		Age  int
+	}
+	type Destination struct {
+		Name string
+		Age  int
+	}
+
+	dst := Destination{}
+	err := NewMapper().Map(Source{Name: 1, Age: 30}, &dst)
+
+	assert.ErrorIs(t, err, ErrMismatchType)
+	assert.Equal(t, 0, dst.Age, "Mapping did not stop at the first error")
+}
+
+func TestMapTo(t *testing.T) {
+	mapper := NewMapper()
+
+	user, err := MapTo[testUser](mapper, testUserDTO{ID: 1, withGetterName: "John"})
+	assert.Nil(t, err, "MapTo returned an error")
+	assert.Equal(t, testUser{ID: 1, Name: "Mr. John"}, user)
+
+	userPtr, err := MapTo[*testUser](mapper, testUserDTO{ID: 1, withGetterName: "John"})
+	assert.Nil(t, err, "MapTo returned an error")
+	assert.Equal(t, &testUser{ID: 1, Name: "Mr. John"}, userPtr)
+
+	_, err = MapTo[testUser](mapper, 1)
+	assert.ErrorIs(t, err, ErrMismatchType)
+}
+
+func TestMapSlice(t *testing.T) {
+	mapper := NewMapper()
+
+	users, err := MapSlice[testUserDTO, testUser](mapper, []testUserDTO{
+		{ID: 1, withGetterName: "John"},
+		{ID: 2, withGetterName: "Jane"},
+	})
+	assert.Nil(t, err, "MapSlice returned an error")
+	assert.Equal(t, []testUser{{ID: 1, Name: "Mr. John"}, {ID: 2, Name: "Mr. Jane"}}, users)
+
+	users, err = MapSlice[testUserDTO, testUser](mapper, nil)
+	assert.Nil(t, err, "MapSlice returned an error")
+	assert.Nil(t, users, "Nil slice not mapped to nil")
+
+	_, err = MapSlice[testAllTypes, testUserWithDifferentSetter](mapper, []testAllTypes{{}})
+	var mappingErr *MappingError
+	assert.ErrorAs(t, err, &mappingErr)
+	assert.Equal(t, "[0].ID", mappingErr.Path, "Path not equal")
+}
+
+func TestMapMap(t *testing.T) {
+	mapper := NewMapper()
+
+	users, err := MapMap[string, testUserDTO, testUser](mapper, map[string]testUserDTO{
+		"john": {ID: 1, withGetterName: "John"},
+	})
+	assert.Nil(t, err, "MapMap returned an error")
+	assert.Equal(t, map[string]testUser{"john": {ID: 1, Name: "Mr. John"}}, users)
+
+	users, err = MapMap[string, testUserDTO, testUser](mapper, nil)
+	assert.Nil(t, err, "MapMap returned an error")
+	assert.Nil(t, users, "Nil map not mapped to nil")
+}
+
+func TestMapWithConverters(t *testing.T) {
+	type Event struct {
+		At time.Time
+	}
+	type Source struct {
+		ID          [2]byte
+		CreatedAt   time.Time
+		UpdatedAt   *time.Time
+		Description sql.NullString
+		Events      []Event
+		Deadlines   map[string]time.Time
+	}
+	type EventDTO struct {
+		At string
+	}
+	type Destination struct {
+		ID          string
+		CreatedAt   string
+		UpdatedAt   *string
+		Description *string
+		Events      []EventDTO
+		Deadlines   map[string]string
+	}
+
+	mapper := NewMapper()
+	err := RegisterConverter(mapper, func(t time.Time) (string, error) {
+		return t.Format(time.RFC3339), nil
+	})
+	assert.Nil(t, err, "RegisterConverter returned an error")
+	err = RegisterConverter(mapper, func(id [2]byte) (string, error) {
+		return fmt.Sprintf("%x", id), nil
+	})
+	assert.Nil(t, err, "RegisterConverter returned an error")
+	err = RegisterConverter(mapper, func(s sql.NullString) (*string, error) {
+		if !s.Valid {
+			return nil, nil
+		}
+		return &s.String, nil
+	})
+	assert.Nil(t, err, "RegisterConverter returned an error")
+
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	src := Source{
+		ID:          [2]byte{0xab, 0xcd},
+		CreatedAt:   now,
+		UpdatedAt:   &now,
+		Description: sql.NullString{String: "test", Valid: true},
+		Events:      []Event{{At: now}},
+		Deadlines:   map[string]time.Time{"review": now},
+	}
+	dst := Destination{}
+	err = mapper.Map(src, &dst)
+
+	formatted := "2024-01-02T03:04:05Z"
+	description := "test"
+	assert.Nil(t, err, "Map returned an error")
+	assert.Equal(t, Destination{
+		ID:          "abcd",
+		CreatedAt:   formatted,
+		UpdatedAt:   &formatted,
+		Description: &description,
+		Events:      []EventDTO{{At: formatted}},
+		Deadlines:   map[string]string{"review": formatted},
+	}, dst)
+
+	dst = Destination{}
+	err = mapper.Map(Source{Description: sql.NullString{}}, &dst)
+	assert.Nil(t, err, "Map returned an error")
+	assert.Nil(t, dst.Description, "Null string not mapped to nil")
+}
+
+func TestMapWithConverterError(t *testing.T) {
+	convertErr := fmt.Errorf("invalid duration")
+	mapper := NewMapper()
+	err := RegisterConverter(mapper, func(d string) (time.Duration, error) {
+		return 0, convertErr
+	})
+	assert.Nil(t, err, "RegisterConverter returned an error")
+
+	dst := struct{ Timeouts []time.Duration }{}
+	err = mapper.Map(struct{ Timeouts []string }{[]string{"1s"}}, &dst)
+
+	var mappingErr *MappingError
+	assert.ErrorAs(t, err, &mappingErr)
+	assert.ErrorIs(t, err, convertErr)
+	assert.Equal(t, "Timeouts[0]", mappingErr.Path, "Path not equal")
+}
+
+func TestRegisterConverterNil(t *testing.T) {
+	err := RegisterConverter[time.Time, string](NewMapper(), nil)
+	assert.Equal(t, fmt.Errorf("convert function must be provided"), err)
+}
+
+func TestRegisterConverterInterface(t *testing.T) {
+	type Result struct {
+		Err error
+	}
+	type ResultDTO struct {
+		Err string
+	}
+	mapper := NewMapper()
+	err := RegisterConverter(mapper, func(e error) (string, error) {
+		if e == nil {
+			return "ok", nil
+		}
+		return e.Error(), nil
+	})
+	assert.Nil(t, err, "RegisterConverter returned an error")
+
+	dto := ResultDTO{}
+	err = mapper.Map(Result{}, &dto)
+	assert.Nil(t, err, "Map returned an error")
+	assert.Equal(t, ResultDTO{Err: "ok"}, dto)
+
+	err = mapper.Map(Result{Err: fmt.Errorf("failed")}, &dto)
+	assert.Nil(t, err, "Map returned an error")
+	assert.Equal(t, ResultDTO{Err: "failed"}, dto)
+}
+
+func TestMapWithStandardConverters(t *testing.T) {
+	type Entity struct {
+		CreatedAt time.Time
+		UpdatedAt time.Time
+		Timeout   time.Duration
+		Name      sql.NullString
+		Age       sql.NullInt64
+		DeletedAt sql.NullTime
+		Metadata  json.RawMessage
+		Balance   *big.Int
+	}
+	type DTO struct {
+		CreatedAt string
+		UpdatedAt int64
+		Timeout   string
+		Name      *string
+		Age       *int64
+		DeletedAt *time.Time
+		Metadata  map[string]any
+		Balance   string
+	}
+
+	mapper := NewMapper()
+	err := RegisterStandardConverters(mapper)
+	assert.Nil(t, err, "RegisterStandardConverters returned an error")
+
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	balance, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	entity := Entity{
+		CreatedAt: now,
+		UpdatedAt: now,
+		Timeout:   90 * time.Second,
+		Name:      sql.NullString{String: "John", Valid: true},
+		Metadata:  json.RawMessage(`{"role":"admin"}`),
+		Balance:   balance,
+	}
+	name := "John"
+	expected := DTO{
+		CreatedAt: "2024-01-02T03:04:05Z",
+		UpdatedAt: now.Unix(),
+		Timeout:   "1m30s",
+		Name:      &name,
+		Metadata:  map[string]any{"role": "admin"},
+		Balance:   "123456789012345678901234567890",
+	}
+
+	dto := DTO{}
+	err = mapper.Map(entity, &dto)
+	assert.Nil(t, err, "Map returned an error")
+	assert.Equal(t, expected, dto)
+
+	mapped := Entity{}
+	err = mapper.Map(dto, &mapped)
+	assert.Nil(t, err, "Map returned an error")
+	assert.True(t, entity.CreatedAt.Equal(mapped.CreatedAt), "CreatedAt not equal")
+	assert.True(t, entity.UpdatedAt.Equal(mapped.UpdatedAt), "UpdatedAt not equal")
+	assert.Equal(t, entity.Timeout, mapped.Timeout, "Timeout not equal")
+	assert.Equal(t, entity.Name, mapped.Name, "Name not equal")
+	assert.Equal(t, entity.Age, mapped.Age, "Age not equal")
+	assert.Equal(t, entity.DeletedAt, mapped.DeletedAt, "DeletedAt not equal")
+	assert.JSONEq(t, string(entity.Metadata), string(mapped.Metadata), "Metadata not equal")
+	assert.Equal(t, 0, entity.Balance.Cmp(mapped.Balance), "Balance not equal")
+}
+
+func TestMapWithStandardConvertersInvalidValue(t *testing.T) {
+	mapper := NewMapper()
+	err := RegisterStandardConverters(mapper)
+	assert.Nil(t, err, "RegisterStandardConverters returned an error")
+
+	dst := struct{ Timeout time.Duration }{}
+	err = mapper.Map(struct{ Timeout string }{"soon"}, &dst)
+	assert.ErrorIs(t, err, ErrMismatchType)
+}
+
+func TestMapFromMap(t *testing.T) {
+	type Address struct {
+		City string
+	}
+	type User struct {
+		ID        int
+		Name      string `map:"full_name"`
+		Email     string
+		Addresses []Address
+		Manager   *Address
+	}
+
+	src := map[string]any{
+		"ID":        1,
+		"full_name": "John Doe",
+		"mail":      "john@example.com",
+		"Addresses": []any{map[string]any{"City": "Springfield"}},
+		"Manager":   map[string]any{"City": "Shelbyville"},
+	}
+	mapper := NewMapper()
+	err := ConfigureFieldMaps[map[string]any, User](mapper, FieldMapConfig{
+		Source:      "mail",
+		Destination: "Email",
+	})
+	assert.Nil(t, err, "ConfigureFieldMaps returned an error")
+
+	user := User{}
+	err = mapper.Map(src, &user)
+	assert.Nil(t, err, "Map returned an error")
+	assert.Equal(t, User{
+		ID:        1,
+		Name:      "John Doe",
+		Email:     "john@example.com",
+		Addresses: []Address{{City: "Springfield"}},
+		Manager:   &Address{City: "Shelbyville"},
+	}, user)
+
+	err = mapper.Map(map[string]any{"ID": "1"}, &user)
+	var mappingErr *MappingError
+	assert.ErrorAs(t, err, &mappingErr)
+	assert.Equal(t, "ID", mappingErr.Path, "Path not equal")
+}
+
+func TestMapToMap(t *testing.T) {
+	type Address struct {
+		City string
+	}
+	type User struct {
+		ID        int
+		Name      string `map:"full_name"`
+		Password  string `map:"-"`
+		Nickname  string `map:",omitempty"`
+		Addresses []Address
+		Manager   *Address
+		Tags      map[string]Address
+		CreatedAt time.Time
+	}
+
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	src := User{
+		ID:        1,
+		Name:      "John Doe",
+		Password:  "secret",
+		Addresses: []Address{{City: "Springfield"}},
+		Manager:   &Address{City: "Shelbyville"},
+		Tags:      map[string]Address{"home": {City: "Springfield"}},
+		CreatedAt: now,
+	}
+
+	dst := map[string]any{}
+	err := NewMapper().Map(src, &dst)
+	assert.Nil(t, err, "Map returned an error")
+	assert.Equal(t, map[string]any{
+		"ID":        1,
+		"full_name": "John Doe",
+		"Addresses": []any{map[string]any{"City": "Springfield"}},
+		"Manager":   map[string]any{"City": "Shelbyville"},
+		"Tags":      map[string]any{"home": map[string]any{"City": "Springfield"}},
+		"CreatedAt": now,
+	}, dst)
+
+	mapper := NewMapper()
+	err = ConfigureFieldMaps[User, map[string]string](mapper, FieldMapConfig{
+		Source:      "Name",
+		Destination: "name",
+	})
+	assert.Nil(t, err, "ConfigureFieldMaps returned an error")
+	var strings map[string]string
+	err = mapper.Map(struct{ Name string }{"John"}, &strings)
+	assert.Nil(t, err, "Map returned an error")
+	assert.Equal(t, map[string]string{"Name": "John"}, strings)
+
+	err = mapper.Map(User{Name: "John"}, &strings)
+	assert.ErrorIs(t, err, ErrMismatchType)
+}
+
+type embeddedBase struct {
+	ID        int
+	CreatedBy string
+}
+
+func TestMapEmbedded(t *testing.T) {
+	type Audit struct {
+		CreatedBy string
+		UpdatedBy string
+	}
+	type UserEntity struct {
+		embeddedBase
+		Name      string
+		UpdatedBy string
+	}
+	type UserDTO struct {
+		ID   int
+		Name string
+		*Audit
+	}
+
+	mapper := NewMapper()
+	dto := UserDTO{}
+	err := mapper.Map(UserEntity{
+		embeddedBase: embeddedBase{ID: 1, CreatedBy: "admin"},
+		Name:         "John",
+		UpdatedBy:    "root",
+	}, &dto)
+	assert.Nil(t, err, "Map returned an error")
+	assert.Equal(t, UserDTO{
+		ID:    1,
+		Name:  "John",
+		Audit: &Audit{CreatedBy: "admin", UpdatedBy: "root"},
+	}, dto)
+
+	entity := UserEntity{}
+	err = mapper.Map(dto, &entity)
+	assert.Nil(t, err, "Map returned an error")
+	assert.Equal(t, UserEntity{
+		embeddedBase: embeddedBase{ID: 1, CreatedBy: "admin"},
+		Name:         "John",
+		UpdatedBy:    "root",
+	}, entity)
+
+	entity = UserEntity{}
+	err = mapper.Map(UserDTO{ID: 2}, &entity)
+	assert.Nil(t, err, "Map returned an error")
+	assert.Equal(t, UserEntity{embeddedBase: embeddedBase{ID: 2}}, entity)
+
+	entity = UserEntity{}
+	err = mapper.Map(map[string]any{"ID": 3, "CreatedBy": "admin"}, &entity)
+	assert.Nil(t, err, "Map returned an error")
+	assert.Equal(t, UserEntity{embeddedBase: embeddedBase{ID: 3, CreatedBy: "admin"}}, entity)
+
+	dst := map[string]any{}
+	err = mapper.Map(UserDTO{ID: 4, Name: "Jane", Audit: &Audit{CreatedBy: "admin"}}, &dst)
+	assert.Nil(t, err, "Map returned an error")
+	assert.Equal(t, map[string]any{
+		"ID":        4,
+		"Name":      "Jane",
+		"CreatedBy": "admin",
+		"UpdatedBy": "",
+	}, dst)
+}
+
+func TestMapEmbeddedSameType(t *testing.T) {
+	type Entity struct {
+		embeddedBase
+		Name string
+	}
+
+	dst := Entity{}
+	err := NewMapper().Map(Entity{embeddedBase: embeddedBase{ID: 1}, Name: "John"}, &dst)
+	assert.Nil(t, err, "Map returned an error")
+	assert.Equal(t, Entity{embeddedBase: embeddedBase{ID: 1}, Name: "John"}, dst)
+}
+
+func TestMapWithFlattening(t *testing.T) {
+	type Country struct {
+		Code string
+	}
+	type Address struct {
+		City    string
+		Country *Country
+	}
+	type User struct {
+		Name    string
+		Address Address
+	}
+	type UserDTO struct {
+		Name               string
+		AddressCity        string
+		AddressCountryCode string
+	}
+
+	mapper := NewMapper(WithFlattening())
+	dto := UserDTO{}
+	err := mapper.Map(User{
+		Name:    "John",
+		Address: Address{City: "Springfield", Country: &Country{Code: "US"}},
+	}, &dto)
+	assert.Nil(t, err, "Map returned an error")
+	assert.Equal(t, UserDTO{
+		Name:               "John",
+		AddressCity:        "Springfield",
+		AddressCountryCode: "US",
+	}, dto)
+
+	user := User{}
+	err = mapper.Map(dto, &user)
+	assert.Nil(t, err, "Map returned an error")
+	assert.Equal(t, User{
+		Name:    "John",
+		Address: Address{City: "Springfield", Country: &Country{Code: "US"}},
+	}, user)
+
+	dto = UserDTO{}
+	err = mapper.Map(User{Name: "John", Address: Address{City: "Springfield"}}, &dto)
+	assert.Nil(t, err, "Map returned an error")
+	assert.Equal(t, UserDTO{Name: "John", AddressCity: "Springfield"}, dto)
+
+	dto = UserDTO{}
+	err = NewMapper().Map(User{Name: "John", Address: Address{City: "Springfield"}}, &dto)
+	assert.Nil(t, err, "Map returned an error")
+	assert.Equal(t, UserDTO{Name: "John"}, dto)
+}
+
+func TestMapWithFlatteningMappingError(t *testing.T) {
+	type Address struct {
+		Zip int
+	}
+	type User struct {
+		Address Address
+	}
+
+	user := User{}
+	err := NewMapper(WithFlattening()).Map(struct{ AddressZip string }{"12345"}, &user)
+	var mappingErr *MappingError
+	assert.ErrorAs(t, err, &mappingErr)
+	assert.Equal(t, "Address.Zip", mappingErr.Path, "Path not equal")
+	assert.ErrorIs(t, err, ErrMismatchType)
+}
+
+type cycleNode struct {
+	Name     string
+	Parent   *cycleNode
+	Children []*cycleNode
+}
+
+type cycleNodeDTO struct {
+	Name     string
+	Parent   *cycleNodeDTO
+	Children []*cycleNodeDTO
+}
+
+func TestMapCycle(t *testing.T) {
+	parent := &cycleNode{Name: "parent"}
+	parent.Children = []*cycleNode{{Name: "child", Parent: parent}}
+
+	dst := cycleNodeDTO{}
+	err := NewMapper().Map(parent, &dst)
+	assert.ErrorIs(t, err, ErrCycle)
+	var mappingErr *MappingError
+	assert.ErrorAs(t, err, &mappingErr)
+	assert.Equal(t, "Children[0].Parent", mappingErr.Path, "Path not equal")
+
+	loose := map[string]any{"Name": "loose"}
+	loose["Self"] = loose
+	var looseDst map[string]any
+	err = NewMapper().Map(loose, &looseDst)
+	assert.ErrorIs(t, err, ErrCycle)
+
+	shared := &cycleNode{Name: "shared"}
+	dst = cycleNodeDTO{}
+	err = NewMapper().Map(cycleNode{Children: []*cycleNode{shared, shared}}, &dst)
+	assert.Nil(t, err, "Map returned an error")
+	assert.Equal(t, 2, len(dst.Children), "Children not mapped")
+	assert.False(t, dst.Children[0] == dst.Children[1], "Children should not be shared")
+}
+
+func TestMapWithPreserveReferences(t *testing.T) {
+	parent := &cycleNode{Name: "parent"}
+	child := &cycleNode{Name: "child", Parent: parent}
+	parent.Children = []*cycleNode{child, child}
+
+	mapper := NewMapper(WithPreserveReferences())
+	var dst *cycleNodeDTO
+	err := mapper.Map(parent, &dst)
+	assert.Nil(t, err, "Map returned an error")
+	assert.Equal(t, "parent", dst.Name)
+	assert.Equal(t, 2, len(dst.Children), "Children not mapped")
+	assert.Equal(t, "child", dst.Children[0].Name)
+	assert.True(t, dst.Children[0] == dst.Children[1], "Children should be shared")
+	assert.True(t, dst.Children[0].Parent == dst, "Parent should reference the mapped parent")
+
+	// A cycle through a non-pointer destination can't be preserved
+	root := &cycleNode{Name: "root"}
+	root.Children = []*cycleNode{root}
+	type NodeValue struct {
+		Name     string
+		Children []NodeValue
+	}
+	err = mapper.Map(root, &NodeValue{})
+	assert.ErrorIs(t, err, ErrCycle)
+}
+
+type accessorUser struct {
+	ID       int
+	Name     string
+	password string
+	*embeddedBase
+	embeddedBase2 embeddedBase
+}
+
+type accessorUserDTO struct {
+	ID       int
+	Name     string
+	Password string
+	password string
+}
+
+func TestMapSameTypeWithUnexportedFields(t *testing.T) {
+	type event struct {
+		At     time.Time
+		AtPtr  *time.Time
+		Amount *big.Int
+	}
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	src := event{At: at, AtPtr: &at, Amount: big.NewInt(42)}
+
+	dst := event{}
+	err := NewMapper().Map(src, &dst)
+	assert.Nil(t, err, "Map returned an error")
+	assert.True(t, at.Equal(dst.At), "At not mapped")
+	assert.True(t, at.Equal(*dst.AtPtr), "AtPtr not mapped")
+	assert.Equal(t, 0, big.NewInt(42).Cmp(dst.Amount), "Amount not mapped")
+
+	dst = event{}
+	err = NewMapper().Map(&src, &dst)
+	assert.Nil(t, err, "Map returned an error")
+	assert.True(t, at.Equal(dst.At), "At not mapped from pointer")
+	assert.True(t, at.Equal(*dst.AtPtr), "AtPtr not mapped from pointer")
+
+	err = NewMapper(WithStrictMode()).Map(src, &event{})
+	assert.Nil(t, err, "Map returned an error in strict mode")
+}
+
+func TestMapUnexportedFields(t *testing.T) {
+	dto := accessorUserDTO{}
+	err := NewMapper().Map(accessorUser{
+		ID:            1,
+		Name:          "John",
+		password:      "secret",
+		embeddedBase:  &embeddedBase{CreatedBy: "admin"},
+		embeddedBase2: embeddedBase{ID: 2},
+	}, &dto)
+	assert.Nil(t, err, "Map returned an error")
+	assert.Equal(t, accessorUserDTO{ID: 1, Name: "John"}, dto)
+
+	user := accessorUser{}
+	err = NewMapper().Map(accessorUserDTO{ID: 1, Name: "John", Password: "secret", password: "secret"}, &user)
+	assert.Nil(t, err, "Map returned an error")
+	assert.Equal(t, accessorUser{ID: 1, Name: "John"}, user)
+
+	user = accessorUser{}
+	err = NewMapper().Map(map[string]any{"ID": 1, "password": "secret", "CreatedBy": "admin"}, &user)
+	assert.Nil(t, err, "Map returned an error")
+	assert.Equal(t, accessorUser{ID: 1}, user)
+
+	type Tagged struct {
+		Password string `map:"password"`
+	}
+	tagged := Tagged{}
+	err = NewMapper().Map(accessorUser{password: "secret"}, &tagged)
+	assert.Nil(t, err, "Map returned an error")
+	assert.Equal(t, Tagged{}, tagged)
+}
+
+func TestMapWithFieldAccessor(t *testing.T) {
+	mapper := NewMapper()
+	err := RegisterFieldAccessor(mapper, "password",
+		func(u accessorUser) string { return u.password },
+		func(u *accessorUser, password string) { u.password = password })
+	assert.Nil(t, err, "RegisterFieldAccessor returned an error")
+	err = RegisterFieldAccessor(mapper, "password", nil,
+		func(u *accessorUserDTO, password string) { u.password = password })
+	assert.Nil(t, err, "RegisterFieldAccessor returned an error")
+
+	dto := accessorUserDTO{}
+	err = mapper.Map(accessorUser{ID: 1, password: "secret"}, &dto)
+	assert.Nil(t, err, "Map returned an error")
+	assert.Equal(t, accessorUserDTO{ID: 1, password: "secret"}, dto)
+
+	user := accessorUser{}
+	err = mapper.Map(dto, &user)
+	assert.Nil(t, err, "Map returned an error")
+	assert.Equal(t, accessorUser{ID: 1}, user, "source password has no getter")
+
+	user = accessorUser{password: "old"}
+	err = mapper.Map(map[string]any{"ID": 1}, &user)
+	assert.Nil(t, err, "Map returned an error")
+	assert.Equal(t, accessorUser{ID: 1, password: "old"}, user)
+
+	err = mapper.Map(map[string]any{"password": "new"}, &user)
+	assert.Nil(t, err, "Map returned an error")
+	assert.Equal(t, accessorUser{ID: 1, password: "new"}, user)
+
+	err = mapper.Map(map[string]any{"password": 1}, &user)
+	assert.ErrorIs(t, err, ErrMismatchType)
+	var mappingErr *MappingError
+	assert.ErrorAs(t, err, &mappingErr)
+	assert.Equal(t, "password", mappingErr.Path, "Path not equal")
+}
+
+func TestRegisterFieldAccessorInvalid(t *testing.T) {
+	mapper := NewMapper()
+	err := RegisterFieldAccessor[accessorUser, string](mapper, "password", nil, nil)
+	assert.EqualError(t, err, "get or set function must be provided")
+
+	err = RegisterFieldAccessor(mapper, "password", func(u accessorUser) int { return 0 }, nil)
+	assert.EqualError(t, err, "obj.accessorUser has no field password of type int")
+
+	err = RegisterFieldAccessor(mapper, "name", func(u int) int { return 0 }, nil)
+	assert.EqualError(t, err, "structT must be a struct")
+}
+
+type cloneHookValue struct {
+	Value  string
+	cloned bool
+}
+
+func (v *cloneHookValue) Clone() *cloneHookValue {
+	return &cloneHookValue{Value: v.Value, cloned: true}
+}
+
+func TestClone(t *testing.T) {
+	type Item struct {
+		Name string
+		Tags []string
+	}
+	type Order struct {
+		ID        int
+		Items     []Item
+		Empty     []Item
+		Nil       []Item
+		Counts    map[string]int
+		Codes     [2]string
+		Next      *Order
+		Data      any
+		Pointer   any
+		CreatedAt time.Time
+		Hook      *cloneHookValue
+		Notify    func()
+		note      string
+	}
+
+	now := time.Now()
+	next := &Order{ID: 2}
+	src := Order{
+		ID:        1,
+		Items:     []Item{{Name: "item", Tags: []string{"a", "b"}}},
+		Empty:     []Item{},
+		Counts:    map[string]int{"a": 1},
+		Codes:     [2]string{"x", "y"},
+		Next:      next,
+		Data:      Item{Name: "data"},
+		Pointer:   next,
+		CreatedAt: now,
+		Hook:      &cloneHookValue{Value: "hook"},
+		Notify:    func() {},
+		note:      "note",
+	}
+
+	dst, err := Clone(src)
+	assert.Nil(t, err, "Clone returned an error")
+	assert.Equal(t, src.ID, dst.ID)
+	assert.Equal(t, src.Items, dst.Items)
+	assert.Equal(t, []Item{}, dst.Empty)
+	assert.Nil(t, dst.Nil)
+	assert.Equal(t, src.Counts, dst.Counts)
+	assert.Equal(t, src.Codes, dst.Codes)
+	assert.Equal(t, src.Next, dst.Next)
+	assert.Equal(t, src.Data, dst.Data)
+	assert.True(t, now.Equal(dst.CreatedAt), "CreatedAt not equal")
+	assert.Equal(t, "note", dst.note)
+	assert.NotNil(t, dst.Notify)
+	assert.Equal(t, &cloneHookValue{Value: "hook", cloned: true}, dst.Hook)
+
+	dst.Items[0].Tags[0] = "changed"
+	dst.Counts["a"] = 2
+	dst.Next.ID = 3
+	assert.Equal(t, "a", src.Items[0].Tags[0], "Items is shared")
+	assert.Equal(t, 1, src.Counts["a"], "Counts is shared")
+	assert.Equal(t, 2, src.Next.ID, "Next is shared")
+	assert.True(t, dst.Pointer.(*Order) == dst.Next, "Pointer should reference the copy of Next")
+
+	hook, err := Clone(&cloneHookValue{Value: "hook"})
+	assert.Nil(t, err, "Clone returned an error")
+	assert.Equal(t, &cloneHookValue{Value: "hook"}, hook, "Clone method called for the cloned value")
+}
+
+func TestCloneCycle(t *testing.T) {
+	parent := &cycleNode{Name: "parent"}
+	parent.Children = []*cycleNode{{Name: "child", Parent: parent}}
+
+	dst, err := Clone(parent)
+	assert.Nil(t, err, "Clone returned an error")
+	assert.False(t, dst == parent, "parent is shared")
+	assert.Equal(t, "child", dst.Children[0].Name)
+	assert.True(t, dst.Children[0].Parent == dst, "Parent should reference the copy of parent")
+
+	loose := map[string]any{}
+	loose["self"] = loose
+	_, err = Clone(loose)
+	assert.ErrorIs(t, err, ErrCycle)
+}
+
+type mergeAddress struct {
+	City string
+	Zip  string
+}
+
+type mergeUser struct {
+	Name    string
+	Age     int
+	Email   *string
+	Address *mergeAddress
+	Tags    []string
+}
+
+type mergeUserPatch struct {
+	Name    string
+	Age     int
+	Email   *string
+	Address *mergeAddress
+	Tags    []string
+}
+
+func TestMapWithMergeStrategy(t *testing.T) {
+	email := "john@example.com"
+	newEmail := "jane@example.com"
+	existing := func() mergeUser {
+		return mergeUser{
+			Name:    "John",
+			Age:     30,
+			Email:   &email,
+			Address: &mergeAddress{City: "Springfield", Zip: "12345"},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		strategy MergeStrategy
+		patch    mergeUserPatch
+		expected mergeUser
+	}{
+		{
+			name:     "Overwrite",
+			strategy: MergeOverwrite,
+			patch:    mergeUserPatch{Name: "Jane"},
+			expected: mergeUser{
+				Name:    "Jane",
+				Email:   &email,
+				Address: &mergeAddress{City: "Springfield", Zip: "12345"},
+			},
+		},
+		{
+			name:     "Default",
+			strategy: MergeDefault,
+			patch:    mergeUserPatch{Name: "Jane", Address: &mergeAddress{City: "Shelbyville"}},
+			expected: mergeUser{Name: "Jane", Email: &email, Address: &mergeAddress{City: "Shelbyville"}},
+		},
+		{
+			name:     "OverwriteNil",
+			strategy: MergeOverwriteNil,
+			patch:    mergeUserPatch{Name: "Jane"},
+			expected: mergeUser{Name: "Jane"},
+		},
+		{
+			name:     "SkipZero",
+			strategy: MergeSkipZero,
+			patch:    mergeUserPatch{Age: 31, Address: &mergeAddress{City: "Shelbyville"}},
+			expected: mergeUser{
+				Name:    "John",
+				Age:     31,
+				Email:   &email,
+				Address: &mergeAddress{City: "Shelbyville", Zip: "12345"},
+			},
+		},
+		{
+			name:     "SkipNilPointer",
+			strategy: MergeSkipNilPointer,
+			patch:    mergeUserPatch{Email: &newEmail},
+			expected: mergeUser{
+				Email:   &newEmail,
+				Address: &mergeAddress{City: "Springfield", Zip: "12345"},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			user := existing()
+			err := NewMapper(WithMergeStrategy(test.strategy)).Map(test.patch, &user)
+			assert.Nil(t, err, "Map returned an error")
+			assert.Equal(t, test.expected, user)
+		})
+	}
+}
+
+func TestMapWithFieldMergeStrategy(t *testing.T) {
+	mapper := NewMapper(WithMergeStrategy(MergeSkipZero))
+	err := ConfigureFieldMaps[mergeUserPatch, mergeUser](mapper, FieldMapConfig{
+		Destination:   "Age",
+		MergeStrategy: MergeOverwrite,
+	})
+	assert.Nil(t, err, "ConfigureFieldMaps returned an error")
+
+	user := mergeUser{Name: "John", Age: 30, Tags: []string{"a"}}
+	err = mapper.Map(mergeUserPatch{}, &user)
+	assert.Nil(t, err, "Map returned an error")
+	assert.Equal(t, mergeUser{Name: "John", Tags: []string{"a"}}, user)
+
+	user = mergeUser{Name: "John", Age: 30}
+	err = NewMapper(WithMergeStrategy(MergeSkipNilPointer)).Map(map[string]any{"Name": nil, "Age": 31}, &user)
+	assert.Nil(t, err, "Map returned an error")
+	assert.Equal(t, mergeUser{Name: "John", Age: 31}, user)
+
+	user = mergeUser{Name: "John", Age: 30}
+	err = NewMapper().Map(map[string]any{"Name": nil}, &user)
+	assert.Nil(t, err, "Map returned an error")
+	assert.Equal(t, mergeUser{Name: "John", Age: 30}, user)
+
+	user = mergeUser{Name: "John", Age: 30}
+	err = NewMapper(WithMergeStrategy(MergeOverwriteNil)).Map(map[string]any{"Name": nil}, &user)
+	assert.Nil(t, err, "Map returned an error")
+	assert.Equal(t, mergeUser{Age: 30}, user)
+}
+
+func TestMapNilPointerDefault(t *testing.T) {
+	type source struct {
+		Name  *string
+		Count *int
+	}
+	type destination struct {
+		Name  string
+		Count *int
+	}
+	count := 1
+	dst := destination{Name: "keep", Count: &count}
+	err := NewMapper().Map(source{}, &dst)
+	assert.Nil(t, err, "Map returned an error")
+	assert.Equal(t, destination{Name: "keep", Count: &count}, dst, "nil source pointers leave the destination untouched")
+}
+
+func TestMapNilPointerConverter(t *testing.T) {
+	type source struct {
+		Name *string
+	}
+	type destination struct {
+		Name string
+	}
+	for _, strategy := range []MergeStrategy{MergeDefault, MergeOverwriteNil} {
+		mapper := NewMapper(WithMergeStrategy(strategy))
+		err := RegisterConverter(mapper, func(p *string) (string, error) {
+			if p == nil {
+				return "N/A", nil
+			}
+			return *p, nil
+		})
+		assert.Nil(t, err, "RegisterConverter returned an error")
+
+		dst := destination{Name: "keep"}
+		err = mapper.Map(source{}, &dst)
+		assert.Nil(t, err, "Map returned an error")
+		assert.Equal(t, destination{Name: "N/A"}, dst, "converter not called for nil pointer")
+	}
+}
+
+type mergeSetterDest struct {
+	name    string
+	setName bool
+}
+
+func (d *mergeSetterDest) SetName(name string) {
+	d.name = name
+	d.setName = true
+}
+
+func TestMapSetterWithMergeStrategy(t *testing.T) {
+	dst := mergeSetterDest{}
+	err := NewMapper(WithMergeStrategy(MergeSkipZero)).Map(struct{ Name string }{}, &dst)
+	assert.Nil(t, err, "Map returned an error")
+	assert.False(t, dst.setName, "SetName should not be called")
+
+	err = NewMapper().Map(struct{ Name string }{}, &dst)
+	assert.Nil(t, err, "Map returned an error")
+	assert.True(t, dst.setName, "SetName should be called")
+}
+
+type collectionItem struct {
+	ID   int
+	Name string
+	Qty  int
+}
+
+type collectionItemDTO struct {
+	ID   int64
+	Name string
+}
+
+type collectionOrder struct {
+	Items  []collectionItem
+	Labels map[string]collectionItem
+}
+
+type collectionOrderDTO struct {
+	Items  []collectionItemDTO
+	Labels map[string]collectionItemDTO
+}
+
+func TestMapWithCollectionStrategy(t *testing.T) {
+	existing := func() collectionOrder {
+		return collectionOrder{
+			Items: []collectionItem{
+				{ID: 1, Name: "a", Qty: 1},
+				{ID: 2, Name: "b", Qty: 2},
+				{ID: 3, Name: "c", Qty: 3},
+			},
+			Labels: map[string]collectionItem{
+				"x": {ID: 1, Name: "x", Qty: 1},
+				"y": {ID: 2, Name: "y", Qty: 2},
+			},
+		}
+	}
+	src := collectionOrderDTO{
+		Items: []collectionItemDTO{
+			{ID: 3, Name: "C"},
+			{ID: 4, Name: "D"},
+			{ID: 1, Name: "A"},
+		},
+		Labels: map[string]collectionItemDTO{
+			"x": {ID: 1, Name: "X"},
+			"z": {ID: 3, Name: "Z"},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		strategy CollectionStrategy
+		expected collectionOrder
+	}{
+		{
+			name:     "Default",
+			strategy: CollectionDefault,
+			expected: collectionOrder{
+				Items: []collectionItem{
+					{ID: 1, Name: "a", Qty: 1},
+					{ID: 2, Name: "b", Qty: 2},
+					{ID: 3, Name: "c", Qty: 3},
+					{ID: 3, Name: "C"},
+					{ID: 4, Name: "D"},
+					{ID: 1, Name: "A"},
+				},
+				Labels: map[string]collectionItem{
+					"x": {ID: 1, Name: "X"},
+					"y": {ID: 2, Name: "y", Qty: 2},
+					"z": {ID: 3, Name: "Z"},
+				},
+			},
+		},
+		{
+			name:     "Replace",
+			strategy: CollectionReplace,
+			expected: collectionOrder{
+				Items: []collectionItem{
+					{ID: 3, Name: "C"},
+					{ID: 4, Name: "D"},
+					{ID: 1, Name: "A"},
+				},
+				Labels: map[string]collectionItem{
+					"x": {ID: 1, Name: "X"},
+					"z": {ID: 3, Name: "Z"},
+				},
+			},
+		},
+		{
+			name:     "MergeByIndex",
+			strategy: CollectionMergeByIndex,
+			expected: collectionOrder{
+				Items: []collectionItem{
+					{ID: 3, Name: "C", Qty: 1},
+					{ID: 4, Name: "D", Qty: 2},
+					{ID: 1, Name: "A", Qty: 3},
+				},
+				Labels: map[string]collectionItem{
+					"x": {ID: 1, Name: "X", Qty: 1},
+					"y": {ID: 2, Name: "y", Qty: 2},
+					"z": {ID: 3, Name: "Z"},
+				},
+			},
+		},
+		{
+			name:     "MergeByKey",
+			strategy: CollectionMergeByKey,
+			expected: collectionOrder{
+				Items: []collectionItem{
+					{ID: 3, Name: "C", Qty: 3},
+					{ID: 4, Name: "D"},
+					{ID: 1, Name: "A", Qty: 1},
+				},
+				Labels: map[string]collectionItem{
+					"x": {ID: 1, Name: "X", Qty: 1},
+					"y": {ID: 2, Name: "y", Qty: 2},
+					"z": {ID: 3, Name: "Z"},
+				},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			order := existing()
+			mapper := NewMapper(WithConversion(ConversionNarrowing), WithCollectionStrategy(test.strategy))
+			err := mapper.Map(src, &order)
+			assert.Nil(t, err, "Map returned an error")
+			assert.Equal(t, test.expected, order)
+		})
+	}
+}
+
+func TestMapWithFieldCollectionStrategy(t *testing.T) {
+	type Child struct {
+		Code  string
+		Value int
+	}
+	type Parent struct {
+		Children []*Child
+		Tags     []string
+		Matrix   [][]string
+	}
+
+	mapper := NewMapper()
+	err := ConfigureFieldMaps[Parent, Parent](mapper, FieldMapConfig{
+		Destination:        "Children",
+		CollectionStrategy: CollectionMergeByKey,
+		CollectionKey:      "Code",
+	}, FieldMapConfig{
+		Destination:        "Tags",
+		CollectionStrategy: CollectionReplace,
+	})
+	assert.Nil(t, err, "ConfigureFieldMaps returned an error")
+
+	existingChild := &Child{Code: "a", Value: 1}
+	dst := Parent{
+		Children: []*Child{existingChild, {Code: "b", Value: 2}},
+		Tags:     []string{"old"},
+		Matrix:   [][]string{{"a"}},
+	}
+	err = mapper.Map(Parent{
+		Children: []*Child{{Code: "a", Value: 10}, {Code: "c", Value: 3}},
+		Tags:     []string{"new"},
+		Matrix:   [][]string{{"b"}},
+	}, &dst)
+	assert.Nil(t, err, "Map returned an error")
+	assert.Equal(t, Parent{
+		Children: []*Child{{Code: "a", Value: 10}, {Code: "c", Value: 3}},
+		Tags:     []string{"new"},
+		Matrix:   [][]string{{"a"}, {"b"}},
+	}, dst)
+	assert.True(t, dst.Children[0] == existingChild, "matching child should be updated in place")
+
+	loose := []map[string]any{{"Code": "a", "Value": 1}}
+	err = NewMapper(WithCollectionStrategy(CollectionMergeByKey, "Code")).Map(
+		[]map[string]any{{"Code": "a", "Value": 2}, {"Code": "b"}}, &loose)
+	assert.Nil(t, err, "Map returned an error")
+	assert.Equal(t, []map[string]any{{"Code": "a", "Value": 2}, {"Code": "b"}}, loose)
+}
+
+func TestMapMergeInterfaceElements(t *testing.T) {
+	dst := []any{1, "b"}
+	err := NewMapper(WithCollectionStrategy(CollectionMergeByIndex)).Map([]any{2}, &dst)
+	assert.Nil(t, err, "Map returned an error")
+	assert.Equal(t, []any{2}, dst)
+}
+
+type strictAddress struct {
+	City    string
+	Zip     string
+	Country string
+}
+
+type strictAddressDTO struct {
+	City     string
+	Zip      string
+	Province string
+}
+
+type strictUser struct {
+	ID        int
+	Name      string
+	Password  string
+	Addresses []*strictAddress
+}
+
+type strictUserDTO struct {
+	ID        int
+	FullName  string
+	Addresses []strictAddressDTO
+}
+
+func TestMapWithStrictMode(t *testing.T) {
+	mapper := NewMapper(WithStrictMode())
+	dst := strictUserDTO{}
+	err := mapper.Map(strictUser{ID: 1}, &dst)
+	assert.ErrorIs(t, err, ErrUnmappedField)
+	var unmappedErr *UnmappedFieldsError
+	assert.ErrorAs(t, err, &unmappedErr)
+	assert.Equal(t, UnmappedFields{
+		Destination: []string{"FullName"},
+		Source:      []string{"Name", "Password"},
+	}, unmappedErr.UnmappedFields)
+	assert.Equal(t, "map obj.strictUser to obj.strictUserDTO: destination fields of obj.strictUserDTO "+
+		"with no source: FullName; unused source fields of obj.strictUser: Name, Password", err.Error())
+
+	err = ConfigureFieldMaps[strictUser, strictUserDTO](mapper, FieldMapConfig{
+		Source:      "Name",
+		Destination: "FullName",
+	})
+	assert.Nil(t, err, "ConfigureFieldMaps returned an error")
+	err = AllowUnmappedFields[strictUser, strictUserDTO](mapper, UnmappedFields{Source: []string{"Password"}})
+	assert.Nil(t, err, "AllowUnmappedFields returned an error")
+	err = mapper.Map(strictUser{ID: 1, Name: "John"}, &dst)
+	assert.Nil(t, err, "Map returned an error")
+	assert.Equal(t, strictUserDTO{ID: 1, FullName: "John"}, dst)
+
+	err = mapper.Map(strictUser{Addresses: []*strictAddress{{City: "Springfield"}}}, &dst)
+	var mappingErr *MappingError
+	assert.ErrorAs(t, err, &mappingErr)
+	assert.Equal(t, "Addresses[0]", mappingErr.Path, "Path not equal")
+	assert.ErrorIs(t, err, ErrUnmappedField)
+
+	err = mapper.Map(map[string]any{"ID": 1}, &dst)
+	assert.Nil(t, err, "Map returned an error")
+}
+
+func TestValidate(t *testing.T) {
+	mapper := NewMapper()
+	err := Validate[strictUser, strictUserDTO](mapper)
+	assert.ErrorIs(t, err, ErrUnmappedField)
+	var errs []string
+	for _, err := range err.(interface{ Unwrap() []error }).Unwrap() {
+		var mappingErr *MappingError
+		assert.ErrorAs(t, err, &mappingErr)
+		var unmappedErr *UnmappedFieldsError
+		assert.ErrorAs(t, err, &unmappedErr)
+		errs = append(errs, fmt.Sprintf("%s %v %v", mappingErr.Path,
+			unmappedErr.Destination, unmappedErr.Source))
+	}
+	assert.Equal(t, []string{
+		" [FullName] [Name Password]",
+		"Addresses [Province] [Country]",
+	}, errs)
+
+	err = ConfigureFieldMaps[strictUser, strictUserDTO](mapper, FieldMapConfig{
+		Source:      "Name",
+		Destination: "FullName",
+	})
+	assert.Nil(t, err, "ConfigureFieldMaps returned an error")
+	err = AllowUnmappedFields[strictUser, strictUserDTO](mapper, UnmappedFields{Source: []string{"Password"}})
+	assert.Nil(t, err, "AllowUnmappedFields returned an error")
+	err = AllowUnmappedFields[strictAddress, strictAddressDTO](mapper, UnmappedFields{
+		Destination: []string{"Province"},
+		Source:      []string{"Country"},
+	})
+	assert.Nil(t, err, "AllowUnmappedFields returned an error")
+	assert.Nil(t, Validate[strictUser, strictUserDTO](mapper))
+	assert.Nil(t, Validate[[]strictUser, []*strictUserDTO](mapper))
+
+	err = AllowUnmappedFields[strictUser, map[string]any](mapper, UnmappedFields{})
+	assert.EqualError(t, err, "sourceT and destinationT must be structs")
+}
+
+func TestValidateEmbeddedAndFlattened(t *testing.T) {
+	type Base struct {
+		ID int
+	}
+	type Address struct {
+		City string
+		Zip  string
+	}
+	type User struct {
+		Base
+		Address Address
+	}
+	type UserDTO struct {
+		ID          int
+		AddressCity string
+	}
+
+	err := Validate[User, UserDTO](NewMapper(WithFlattening()))
+	assert.Nil(t, err, "partially used nested fields are used")
+
+	err = Validate[UserDTO, User](NewMapper(WithFlattening()))
+	var unmappedErr *UnmappedFieldsError
+	assert.ErrorAs(t, err, &unmappedErr)
+	assert.Equal(t, UnmappedFields{Destination: []string{"Address.Zip"}}, unmappedErr.UnmappedFields)
+}
+
+type explainBase struct {
+	ID int
+}
+
+type explainUser struct {
+	explainBase
+	Name      string
+	Email     string `map:"email"`
+	Street    string
+	Address   struct{ City string }
+	CreatedAt time.Time
+	age       int
+}
+
+func (u explainUser) GetAge() int {
+	return u.age
+}
+
+type explainUserDTO struct {
+	ID          int
+	FullName    string
+	Mail        string `map:"email"`
+	AddressCity string
+	CreatedAt   string
+	Token       string
+	Nickname    string
+	age         int
+}
+
+func (u *explainUserDTO) SetAge(age int) {
+	u.age = age
+}
+
+func TestExplain(t *testing.T) {
+	mapper := NewMapper(WithFlattening())
+	err := ConfigureFieldMaps[explainUser, explainUserDTO](mapper, FieldMapConfig{
+		Source:      "Name",
+		Destination: "FullName",
+	}, FieldMapConfig{
+		Destination: "Token",
+		GetDestinationValue: func(source any) (any, error) {
+			return "token", nil
+		},
+	})
+	assert.Nil(t, err, "ConfigureFieldMaps returned an error")
+	err = RegisterTimeConverters(mapper)
+	assert.Nil(t, err, "RegisterTimeConverters returned an error")
+
+	plan, err := Explain[explainUser, explainUserDTO](mapper)
+	assert.Nil(t, err, "Explain returned an error")
+	assert.Equal(t, &MappingPlan{
+		SourceType:      "obj.explainUser",
+		DestinationType: "obj.explainUserDTO",
+		Fields: []FieldMapping{
+			{Destination: "ID", DestinationType: "int", Kind: MappingField, Source: "ID", SourceType: "int"},
+			{Destination: "FullName", DestinationType: "string", Kind: MappingRenamed, Source: "Name", SourceType: "string"},
+			{Destination: "Mail", DestinationType: "string", Kind: MappingRenamed, Source: "Email", SourceType: "string"},
+			{Destination: "AddressCity", DestinationType: "string", Kind: MappingFlattened, Source: "Address.City",
+				SourceType: "string"},
+			{Destination: "CreatedAt", DestinationType: "string", Kind: MappingField, Source: "CreatedAt",
+				SourceType: "time.Time", Converter: true},
+			{Destination: "Token", DestinationType: "string", Kind: MappingFunc},
+			{Destination: "Nickname", DestinationType: "string", Kind: MappingUnmapped},
+			{Destination: "Age", DestinationType: "int", Setter: "SetAge", Kind: MappingGetter, Source: "GetAge",
+				SourceType: "int"},
+		},
+	}, plan)
+
+	expected := `obj.explainUser -> obj.explainUserDTO
+  ID           int     <-  ID            int        field
+  FullName     string  <-  Name          string     renamed
+  Mail         string  <-  Email         string     renamed
+  AddressCity  string  <-  Address.City  string     flattened
+  CreatedAt    string  <-  CreatedAt     time.Time  field, converter
+  Token        string  <-                           func
+  Nickname     string  <-                           unmapped
+  Age          int     <-  GetAge        int        getter, SetAge
+`
+	assert.Equal(t, expected, plan.String())
+
+	data, err := json.Marshal(plan.Fields[0])
+	assert.Nil(t, err, "Marshal returned an error")
+	assert.JSONEq(t, `{"destination":"ID","destinationType":"int","kind":"field","source":"ID","sourceType":"int"}`,
+		string(data))
+}
+
+func TestExplainMaps(t *testing.T) {
+	type User struct {
+		ID      int
+		Address struct{ City string }
+	}
+
+	plan, err := Explain[map[string]any, User](NewMapper(WithFlattening()))
+	assert.Nil(t, err, "Explain returned an error")
+	assert.Equal(t, []FieldMapping{
+		{Destination: "ID", DestinationType: "int", Kind: MappingKey, Source: "ID"},
+		{Destination: "Address", DestinationType: "struct { City string }", Kind: MappingKey, Source: "Address"},
+	}, plan.Fields)
+
+	plan, err = Explain[User, map[string]any](NewMapper())
+	assert.Nil(t, err, "Explain returned an error")
+	assert.Equal(t, []FieldMapping{
+		{Destination: "ID", DestinationType: "interface {}", Kind: MappingField, Source: "ID", SourceType: "int"},
+		{Destination: "Address", DestinationType: "interface {}", Kind: MappingField, Source: "Address",
+			SourceType: "struct { City string }"},
+	}, plan.Fields)
+
+	_, err = Explain[map[string]any, map[string]any](NewMapper())
+	assert.EqualError(t, err, "sourceT and destinationT must be structs, or a struct and a map with string keys")
+}
+
+type genAddress struct {
+	City string
+	Zip  int
+}
+
+type genAddressDTO struct {
+	City string
+	Zip  int
+}
+
+type genBase struct {
+	ID int64
+}
+
+type genUser struct {
+	genBase
+	Name      string
+	Email     string
+	Nickname  string
+	Address   genAddress
+	Previous  *genAddress
+	Tags      []string
+	CreatedAt time.Time
+	Location  struct{ Lat float64 }
+	age       int
+}
+
+func (u genUser) GetAge() int {
+	return u.age
+}
+
+type genUserDTO struct {
+	ID           int64
+	FullName     string `map:"Name"`
+	Email        string
+	Nickname     string `map:",omitempty"`
+	Address      genAddressDTO
+	Previous     *genAddressDTO
+	Tags         []string
+	CreatedAt    string
+	LocationLat  float64
+	Token        string
+	Verified     bool
+	age          int
+	ageSet       bool
+	unmappedNote string
+}
+
+func (u *genUserDTO) SetAge(age int) {
+	u.age = age
+	u.ageSet = true
+}
+
+func newGenMapper() *Mapper {
+	mapper := NewMapper(WithFlattening())
+	_ = ConfigureFieldMaps[genUser, genUserDTO](mapper, FieldMapConfig{
+		Destination: "Token",
+		GetDestinationValue: func(source any) (any, error) {
+			return "token", nil
+		},
+	}, FieldMapConfig{
+		Destination:   "Email",
+		MergeStrategy: MergeSkipZero,
+	})
+	_ = RegisterTimeConverters(mapper)
+	return mapper
+}
+
+var genPairs = []GeneratePair{
+	{
+		Source:      reflect.TypeFor[genUser](),
+		Destination: reflect.TypeFor[genUserDTO](),
+		FuncName:    "mapGenUser",
+	},
+	{
+		Source:      reflect.TypeFor[genAddress](),
+		Destination: reflect.TypeFor[genAddressDTO](),
+		FuncName:    "mapGenAddress",
+	},
+}
+
+func TestGenerate(t *testing.T) {
+	var generated bytes.Buffer
+	err := Generate(&generated, newGenMapper(), GenerateConfig{
+		PackageName: "obj",
+		PackagePath: reflect.TypeFor[Mapper]().PkgPath(),
+		Pairs:       genPairs,
+	})
+	assert.Nil(t, err, "Generate returned an error")
+
+	expected, err := os.ReadFile("mappers_gen_test.go")
+	assert.Nil(t, err, "ReadFile returned an error")
+	assert.Equal(t, string(expected), generated.String(), "mappers_gen_test.go is not up to date")
+
+	err = Generate(&generated, newGenMapper(), GenerateConfig{
+		PackageName: "obj",
+		Pairs:       []GeneratePair{PairOf[genUser, map[string]any]()},
+	})
+	assert.EqualError(t, err, "source and destination of pairs must be structs")
+}
+
+func TestGenerateOtherPackage(t *testing.T) {
+	var generated bytes.Buffer
+	err := Generate(&generated, NewMapper(), GenerateConfig{
+		PackageName: "dto",
+		PackagePath: "example.com/dto",
+		Pairs:       []GeneratePair{PairOf[genAddress, genAddressDTO]()},
+	})
+	assert.Nil(t, err, "Generate returned an error")
+	assert.Equal(t, `// Code generated by goeasy-mapgen. DO NOT EDIT.
+
+package dto
+
+import (
+	obj "github.com/bryan-t/goeasy/obj"
+)
+
+// MapgenAddressTogenAddressDTO maps src to dst like mapper.Map(src, dst).
+func MapgenAddressTogenAddressDTO(mapper *obj.Mapper, src *obj.genAddress, dst *obj.genAddressDTO) error {
+	dst.City = src.City
+	dst.Zip = src.Zip
+	return nil
+}
+`, generated.String())
+
+	generated.Reset()
+	err = Generate(&generated, NewMapper(WithCollectErrors()), GenerateConfig{
+		PackageName: "obj",
+		PackagePath: reflect.TypeFor[Mapper]().PkgPath(),
+		Pairs:       []GeneratePair{PairOf[genAddress, genAddressDTO]()},
+	})
+	assert.Nil(t, err, "Generate returned an error")
+	assert.Contains(t, generated.String(), "return mapper.Map(src, dst)")
+}
+
+func TestVerifyGenerated(t *testing.T) {
+	mapper := newGenMapper()
+	assert.Nil(t, VerifyGenerated(mapper, mapGenUser))
+	assert.Nil(t, VerifyGenerated(mapper, mapGenAddress))
+
+	err := VerifyGenerated(mapper, func(mapper *Mapper, src *genAddress, dst *genAddressDTO) error {
+		dst.City = src.City
+		return nil
+	})
+	assert.ErrorContains(t, err, "generated mapping of")
+}
+
+func TestMapField(t *testing.T) {
+	mapper := newGenMapper()
+	src := genUser{Address: genAddress{City: "Springfield"}, Location: struct{ Lat float64 }{1.5}, age: 30}
+	dst := genUserDTO{}
+
+	assert.Nil(t, MapField(mapper, &src, &dst, "Address"))
+	assert.Nil(t, MapField(mapper, &src, &dst, "LocationLat"))
+	assert.Nil(t, MapField(mapper, &src, &dst, "SetAge"))
+	assert.Equal(t, genUserDTO{
+		Address:     genAddressDTO{City: "Springfield"},
+		LocationLat: 1.5,
+		age:         30,
+		ageSet:      true,
+	}, dst)
+
+	err := MapField(mapper, &src, &dst, "Unknown")
+	assert.ErrorIs(t, err, ErrFieldNotFound)
+}
+
+type namingMessage struct {
+	name string
+	age  int
+}
+
+func (m namingMessage) Name() string {
+	return m.name
+}
+
+func (m *namingMessage) GetAge() int {
+	return m.age
+}
+
+type namingBuilder struct {
+	name string
+	age  int
+}
+
+func (b namingBuilder) WithName(name string) namingBuilder {
+	b.name = name
+	return b
+}
+
+func (b *namingBuilder) WithAge(age int) *namingBuilder {
+	b.age = age
+	return b
+}
+
+func (b namingBuilder) Without(name string) namingBuilder {
+	return namingBuilder{}
+}
+
+func TestMapWithNamingStrategy(t *testing.T) {
+	mapper := NewMapper(WithNamingStrategy(NamingStrategy{
+		GetterPrefixes:   []string{"", "Get"},
+		SetterPrefixes:   []string{"With"},
+		PointerReceivers: true,
+		FluentSetters:    true,
+	}))
+	src := namingMessage{name: "John", age: 30}
+
+	dst := namingBuilder{}
+	err := mapper.Map(src, &dst)
+	assert.Nil(t, err, "Map returned an error")
+	assert.Equal(t, namingBuilder{name: "John", age: 30}, dst)
+
+	dst = namingBuilder{}
+	err = mapper.Map(&src, &dst)
+	assert.Nil(t, err, "Map returned an error")
+	assert.Equal(t, namingBuilder{name: "John", age: 30}, dst)
+
+	user := testUser{}
+	err = mapper.Map(src, &user)
+	assert.Nil(t, err, "Map returned an error")
+	assert.Equal(t, testUser{Name: "John"}, user)
+}
+
+func TestMapWithDefaultNamingStrategy(t *testing.T) {
+	src := namingMessage{name: "John", age: 30}
+	dst := namingBuilder{}
+	err := NewMapper().Map(src, &dst)
+	assert.Nil(t, err, "Map returned an error")
+	assert.Equal(t, namingBuilder{}, dst, "exact name getters, pointer receivers and fluent setters are not mapped")
+}
+
+func TestMapWithoutMethods(t *testing.T) {
+	mapper := NewMapper(WithNamingStrategy(NamingStrategy{}))
+	dto := testUserDTO{
+		ID:             1,
+		withGetterName: "John",
+	}
+
+	user := testUser{}
+	err := mapper.Map(dto, &user)
+	assert.Nil(t, err, "Map returned an error")
+	assert.Equal(t, testUser{ID: 1}, user)
+
+	userWithSetter := testUserWithSetter{}
+	err = mapper.Map(dto, &userWithSetter)
+	assert.Nil(t, err, "Map returned an error")
+	assert.Equal(t, testUserWithSetter{}, userWithSetter)
+}
+
+func TestExplainWithNamingStrategy(t *testing.T) {
+	mapper := NewMapper(WithNamingStrategy(NamingStrategy{
+		GetterPrefixes:   []string{"Get"},
+		SetterPrefixes:   []string{"With"},
+		PointerReceivers: true,
+		FluentSetters:    true,
+	}))
+	plan, err := Explain[namingMessage, namingBuilder](mapper)
+	assert.Nil(t, err, "Explain returned an error")
+	assert.Contains(t, plan.Fields, FieldMapping{
+		Destination: "Age", DestinationType: "int", Setter: "WithAge", Kind: MappingGetter, Source: "GetAge",
+		SourceType: "int",
+	})
+}
+
+type matchingUser struct {
+	UserID    int
+	FirstName string `map:"first_name"`
+	Address   matchingAddress
+}
+
+type matchingAddress struct {
+	ZipCode string
+}
+
+type matchingUserDTO struct {
+	UserId         int
+	First_Name     string
+	AddressZipCode string
+}
+
+type matchingAccount struct {
+	userID int
+}
+
+func (a matchingAccount) GetUserId() int {
+	return a.userID
+}
+
+type matchingAccountDTO struct {
+	userID int
+}
+
+func (a *matchingAccountDTO) SetUserID(id int) {
+	a.userID = id
+}
+
+func TestMapWithNameMatching(t *testing.T) {
+	src := matchingUser{UserID: 1, FirstName: "John", Address: matchingAddress{ZipCode: "12345"}}
+
+	dst := matchingUserDTO{}
+	err := NewMapper(WithFlattening()).Map(src, &dst)
+	assert.Nil(t, err, "Map returned an error")
+	assert.Equal(t, matchingUserDTO{AddressZipCode: "12345"}, dst, "names match exactly by default")
+
+	dst = matchingUserDTO{}
+	err = NewMapper(WithNameMatching(MatchCaseInsensitive)).Map(src, &dst)
+	assert.Nil(t, err, "Map returned an error")
+	assert.Equal(t, matchingUserDTO{UserId: 1, First_Name: "John"}, dst)
+
+	dst = matchingUserDTO{}
+	err = NewMapper(WithNameMatching(MatchNormalized), WithFlattening()).Map(src, &dst)
+	assert.Nil(t, err, "Map returned an error")
+	assert.Equal(t, matchingUserDTO{UserId: 1, First_Name: "John", AddressZipCode: "12345"}, dst)
+}
+
+func TestMapWithNameMatchingMethods(t *testing.T) {
+	dst := matchingAccountDTO{}
+	err := NewMapper(WithNameMatching(MatchCaseInsensitive)).Map(matchingAccount{userID: 1}, &dst)
+	assert.Nil(t, err, "Map returned an error")
+	assert.Equal(t, matchingAccountDTO{userID: 1}, dst)
+}
+
+func TestMapWithNameMatchingMapKeys(t *testing.T) {
+	mapper := NewMapper(WithNameMatching(MatchNormalized))
+
+	dst := matchingUserDTO{}
+	err := mapper.Map(map[string]any{"user_id": 1, "first-name": "John", "UserId": 2}, &dst)
+	assert.Nil(t, err, "Map returned an error")
+	assert.Equal(t, matchingUserDTO{UserId: 2, First_Name: "John"}, dst, "exact keys are preferred")
+
+	dst = matchingUserDTO{}
+	err = mapper.Map(map[string]any{"user_id": 1, "userId": 2}, &dst)
+	assert.Nil(t, err, "Map returned an error")
+	assert.Equal(t, matchingUserDTO{UserId: 2}, dst, "the smallest matching key is used")
+
+	user := matchingUser{}
+	err = mapper.Map(map[string]any{"user-id": 1, "first_name": "John"}, &user)
+	assert.Nil(t, err, "Map returned an error")
+	assert.Equal(t, matchingUser{UserID: 1, FirstName: "John"}, user)
+}
+
+func TestMapWithCustomNameMatching(t *testing.T) {
+	mapper := NewMapper(WithNameMatching(func(name string) string {
+		return strings.TrimPrefix(name, "Src")
+	}))
+	src := struct{ SrcName string }{SrcName: "John"}
+	dst := testUser{}
+	err := mapper.Map(src, &dst)
+	assert.Nil(t, err, "Map returned an error")
+	assert.Equal(t, testUser{Name: "John"}, dst)
+}
@@ -0,0 +1,210 @@
+package obj
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// MappingError is returned by Mapper when a value fails to be mapped. It wraps
+// the cause, e.g. ErrMismatchType, so errors.Is can be used on it.
+type MappingError struct {
+	// Path is the path of the value which failed to be mapped, e.g.
+	// Orders[3].Address.Zip. It is empty if the value passed to Map failed.
+	Path string
+
+	// SourceType is the type of the source value
+	SourceType reflect.Type
+
+	// DestinationType is the type of the destination value
+	DestinationType reflect.Type
+
+	// Err is the cause of the error
+	Err error
+}
+
+func (e *MappingError) Error() string {
+	if e.Path == "" {
+		return fmt.Sprintf("map %s to %s: %v", e.SourceType, e.DestinationType, e.Err)
+	}
+	return fmt.Sprintf("map %s (%s to %s): %v", e.Path, e.SourceType, e.DestinationType, e.Err)
+}
+
+func (e *MappingError) Unwrap() error {
+	return e.Err
+}
+
+// pathSegment is a single step of the path to a mapped value: a field, an index
+// of an array or slice, or a key of a map.
+type pathSegment struct {
+	field string
+	index int
+	key   reflect.Value
+}
+
+// pathCapacity is the initial capacity of the path of a mapState, enough for most
+// mappings not to grow it.
+const pathCapacity = 8
+
+// mapState holds the state of a single Map call. It doesn't escape the call, so
+// that it is allocated on the stack along with its initial path.
+type mapState struct {
+	path []pathSegment
+
+	// collectErrors indicates whether mapping continues after an error, in which
+	// case the errors are recorded in errs.
+	collectErrors bool
+	errs          []error
+
+	// field is the configuration of the field at path depth fieldDepth
+	field      *FieldMapConfig
+	fieldDepth int
+
+	// visiting holds the source pointers and maps being mapped to detect cycles
+	visiting map[reference]struct{}
+
+	// refs holds the destination pointers source pointers were mapped to when
+	// references are preserved
+	refs map[reference]reflect.Value
+}
+
+// reference identifies a source pointer or map, and the destination type it is
+// mapped to when references are preserved.
+type reference struct {
+	pointer uintptr
+	srcType reflect.Type
+	dstType reflect.Type
+}
+
+// enter records that src is being mapped. Returns false if src is already being
+// mapped, meaning src references itself.
+func (s *mapState) enter(src reflect.Value) bool {
+	ref := reference{pointer: src.Pointer(), srcType: src.Type()}
+	if _, ok := s.visiting[ref]; ok {
+		return false
+	}
+	if s.visiting == nil {
+		s.visiting = make(map[reference]struct{})
+	}
+	s.visiting[ref] = struct{}{}
+	return true
+}
+
+func (s *mapState) leave(src reflect.Value) {
+	delete(s.visiting, reference{pointer: src.Pointer(), srcType: src.Type()})
+}
+
+// mapped returns the destination pointer of type dstType src was mapped to.
+func (s *mapState) mapped(src reflect.Value, dstType reflect.Type) (reflect.Value, bool) {
+	dst, ok := s.refs[reference{pointer: src.Pointer(), srcType: src.Type(), dstType: dstType}]
+	return dst, ok
+}
+
+func (s *mapState) setMapped(src reflect.Value, dst reflect.Value) {
+	if s.refs == nil {
+		s.refs = make(map[reference]reflect.Value)
+	}
+	ref := reference{pointer: src.Pointer(), srcType: src.Type(), dstType: dst.Type()}
+	s.refs[ref] = reflect.ValueOf(dst.Interface())
+}
+
+func (s *mapState) pushField(name string) {
+	s.push(pathSegment{field: name})
+}
+
+func (s *mapState) pushIndex(index int) {
+	s.push(pathSegment{index: index})
+}
+
+func (s *mapState) pushKey(key reflect.Value) {
+	s.push(pathSegment{key: key})
+}
+
+func (s *mapState) push(segment pathSegment) {
+	appendInPlace(&s.path, segment)
+}
+
+// appendInPlace appends v to *s. Unlike append, it keeps the backing arrays of the
+// fields of a mapState, e.g. its initial path, from escaping to the heap.
+func appendInPlace[T any](s *[]T, v T) {
+	if len(*s) == cap(*s) {
+		grown := make([]T, len(*s), 2*len(*s)+1)
+		copy(grown, *s)
+		*s = grown
+	}
+	*s = (*s)[:len(*s)+1]
+	(*s)[len(*s)-1] = v
+}
+
+func (s *mapState) pop() {
+	s.path = s.path[:len(s.path)-1]
+	if s.fieldDepth > len(s.path) {
+		s.field = nil
+	}
+}
+
+// setFieldMap records the configuration of the field just pushed.
+func (s *mapState) setFieldMap(fieldMap *FieldMapConfig) {
+	s.field, s.fieldDepth = fieldMap, len(s.path)
+}
+
+// fieldMap returns the configuration of the field being mapped, nil if none or
+// if the value being mapped is not the field itself, e.g. an element of it.
+func (s *mapState) fieldMap() *FieldMapConfig {
+	if s.fieldDepth != len(s.path) {
+		return nil
+	}
+	return s.field
+}
+
+// pathString formats the current path, e.g. Orders[3].Address.Zip
+func (s *mapState) pathString() string {
+	var sb strings.Builder
+	for _, segment := range s.path {
+		switch {
+		case segment.field != "":
+			if sb.Len() > 0 {
+				sb.WriteByte('.')
+			}
+			sb.WriteString(segment.field)
+		case segment.key.IsValid():
+			fmt.Fprintf(&sb, "[%v]", segment.key.Interface())
+		default:
+			sb.WriteString("[" + strconv.Itoa(segment.index) + "]")
+		}
+	}
+	return sb.String()
+}
+
+// fail wraps err in a *MappingError. When collecting errors, the error is recorded
+// and nil is returned so that mapping continues.
+func (s *mapState) fail(err error, srcType reflect.Type, dstType reflect.Type) error {
+	err = s.wrapError(err, srcType, dstType)
+	if s.collectErrors {
+		appendInPlace(&s.errs, err)
+		return nil
+	}
+	return err
+}
+
+// wrapError wraps err in a *MappingError with the current path, unless it already is one.
+func (s *mapState) wrapError(err error, srcType reflect.Type, dstType reflect.Type) error {
+	var mappingErr *MappingError
+	if errors.As(err, &mappingErr) {
+		return err
+	}
+
+	path := s.pathString()
+	var overflowErr *OverflowError
+	if errors.As(err, &overflowErr) && overflowErr.Field == "" {
+		overflowErr.Field = path
+	}
+	return &MappingError{
+		Path:            path,
+		SourceType:      srcType,
+		DestinationType: dstType,
+		Err:             err,
+	}
+}
@@ -0,0 +1,66 @@
+package obj
+
+import "reflect"
+
+// MergeStrategy defines whether a source field overwrites the destination field,
+// e.g. to apply a partial update to an existing value.
+type MergeStrategy int
+
+const (
+	// MergeDefault uses the merge strategy of the Mapper, or MergeOverwrite if none.
+	MergeDefault MergeStrategy = iota
+
+	// MergeOverwrite overwrites the destination field. A nil source pointer or
+	// interface has no value to map and leaves the destination field untouched,
+	// unless a converter is registered for it.
+	MergeOverwrite
+
+	// MergeSkipZero leaves the destination field untouched when the source value is zero.
+	MergeSkipZero
+
+	// MergeSkipNilPointer leaves the destination field untouched when the source
+	// value is a nil pointer or interface, e.g. a field omitted from a patch request.
+	MergeSkipNilPointer
+
+	// MergeOverwriteNil always overwrites the destination field. Unlike MergeOverwrite,
+	// a nil source pointer or interface sets the destination field to its zero value,
+	// unless a converter is registered for it.
+	MergeOverwriteNil
+)
+
+// mergeStrategy returns the merge strategy of a field, fieldMap being nil if the
+// field is not configured.
+func (m *Mapper) mergeStrategy(fieldMap *FieldMapConfig) MergeStrategy {
+	if fieldMap != nil && fieldMap.MergeStrategy != MergeDefault {
+		return fieldMap.MergeStrategy
+	}
+	if m.cfg.mergeStrategy != MergeDefault {
+		return m.cfg.mergeStrategy
+	}
+	return MergeOverwrite
+}
+
+// skipMerge returns true if src is not to be merged into the destination.
+func skipMerge(strategy MergeStrategy, src reflect.Value) bool {
+	switch strategy {
+	case MergeSkipZero:
+		return !src.IsValid() || src.IsZero()
+	case MergeSkipNilPointer:
+		return !src.IsValid() || isNilPointer(src)
+	}
+	return false
+}
+
+// zeroNil returns true if the destination dst is set to its zero value for src
+// according to strategy.
+func (m *Mapper) zeroNil(strategy MergeStrategy, src reflect.Value, dst reflect.Value) bool {
+	if strategy != MergeOverwriteNil || !src.IsValid() || !isNilPointer(src) {
+		return false
+	}
+	_, ok := m.converter(src, dst)
+	return !ok
+}
+
+func isNilPointer(v reflect.Value) bool {
+	return (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) && v.IsNil()
+}
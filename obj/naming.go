@@ -0,0 +1,177 @@
+package obj
+
+import (
+	"reflect"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// NameMatching defines which source field, getter or map key matches the name of a
+// destination field or setter: names match if they are equal once normalized by the
+// NameMatching. Names matching exactly are always preferred.
+type NameMatching func(name string) string
+
+var (
+	// MatchExact matches identical names only. It is used unless configured otherwise
+	// with WithNameMatching.
+	MatchExact NameMatching = func(name string) string {
+		return name
+	}
+
+	// MatchCaseInsensitive matches names differing in case, e.g. UserID and UserId.
+	MatchCaseInsensitive NameMatching = strings.ToLower
+
+	// MatchNormalized matches names differing in case or in their "_" and "-"
+	// separators, e.g. UserID, userId, user_id and user-id.
+	MatchNormalized NameMatching = func(name string) string {
+		return strings.ToLower(nameSeparators.Replace(name))
+	}
+)
+
+var nameSeparators = strings.NewReplacer("_", "", "-", "")
+
+// normalizeName returns name normalized by the name matching of the Mapper.
+func (m *Mapper) normalizeName(name string) string {
+	if m.cfg.nameMatching == nil {
+		return name
+	}
+	return m.cfg.nameMatching(name)
+}
+
+// exportedField returns the exported field of t matching name.
+func (m *Mapper) exportedField(t reflect.Type, name string) (reflect.StructField, bool) {
+	if field, ok := t.FieldByName(name); ok && field.IsExported() {
+		return field, true
+	}
+	if m.cfg.nameMatching == nil {
+		return reflect.StructField{}, false
+	}
+
+	normalized := m.cfg.nameMatching(name)
+	for _, field := range reflect.VisibleFields(t) {
+		if field.IsExported() && m.cfg.nameMatching(field.Name) == normalized {
+			return field, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// method returns the exported method of t matching name.
+func (m *Mapper) method(t reflect.Type, name string) (reflect.Method, bool) {
+	if method, ok := t.MethodByName(name); ok || m.cfg.nameMatching == nil {
+		return method, ok
+	}
+
+	normalized := m.cfg.nameMatching(name)
+	for i := 0; i < t.NumMethod(); i++ {
+		if method := t.Method(i); m.cfg.nameMatching(method.Name) == normalized {
+			return method, true
+		}
+	}
+	return reflect.Method{}, false
+}
+
+// mapIndexMatching returns the value of the key of the map src matching key, the
+// smallest key if several match. Returns the zero Value if none matches.
+func mapIndexMatching(src reflect.Value, key string, matching NameMatching) reflect.Value {
+	normalized := matching(key)
+	var matchedKey, value reflect.Value
+	iter := src.MapRange()
+	for iter.Next() {
+		if matching(iter.Key().String()) != normalized {
+			continue
+		}
+		if !matchedKey.IsValid() || iter.Key().String() < matchedKey.String() {
+			matchedKey, value = iter.Key(), iter.Value()
+		}
+	}
+	return value
+}
+
+// NamingStrategy defines the methods mapped as if they were fields: getters of the
+// source, e.g. GetName() string, and setters of the destination, e.g. SetName(string).
+// The zero NamingStrategy maps no methods.
+type NamingStrategy struct {
+	// GetterPrefixes are the prefixes of getter names, tried in order. The empty
+	// prefix matches getters named after the field, e.g. Name() string.
+	GetterPrefixes []string
+
+	// SetterPrefixes are the prefixes of setter names, e.g. "With" for WithName.
+	// Empty prefixes are ignored.
+	SetterPrefixes []string
+
+	// PointerReceivers allows getters with pointer receivers, e.g. of protobuf messages.
+	PointerReceivers bool
+
+	// FluentSetters allows setters returning the destination, by value or pointer,
+	// e.g. WithName(string) T. The returned value is stored in the destination.
+	FluentSetters bool
+}
+
+// DefaultNamingStrategy maps Get* methods of the source and Set* methods of the
+// destination. It is used unless configured otherwise with WithNamingStrategy.
+var DefaultNamingStrategy = NamingStrategy{
+	GetterPrefixes: []string{"Get"},
+	SetterPrefixes: []string{"Set"},
+}
+
+func (m *Mapper) namingStrategy() NamingStrategy {
+	if m.cfg.namingStrategy != nil {
+		return *m.cfg.namingStrategy
+	}
+	return DefaultNamingStrategy
+}
+
+// getterIndex returns the index of the getter of srcType for the field name, -1 if
+// there is none. pointer is true if the index is of a method of the pointer to srcType.
+func (m *Mapper) getterIndex(srcType reflect.Type, name string) (index int, pointer bool) {
+	naming := m.namingStrategy()
+	for _, prefix := range naming.GetterPrefixes {
+		if method, ok := m.method(srcType, prefix+name); ok && isGetter(method) {
+			return method.Index, false
+		}
+		if !naming.PointerReceivers || srcType.Kind() == reflect.Pointer {
+			continue
+		}
+		if method, ok := m.method(reflect.PointerTo(srcType), prefix+name); ok && isGetter(method) {
+			return method.Index, true
+		}
+	}
+	return -1, false
+}
+
+func isGetter(method reflect.Method) bool {
+	return method.Type.NumIn() == 1 && method.Type.NumOut() == 1
+}
+
+// setterName returns the name of the field set by method of the pointer to dstType,
+// false if method is not a setter.
+func (m *Mapper) setterName(dstType reflect.Type, method reflect.Method) (string, bool) {
+	naming := m.namingStrategy()
+	if method.Type.NumIn() != 2 {
+		return "", false
+	}
+	switch method.Type.NumOut() {
+	case 0:
+	case 1:
+		out := method.Type.Out(0)
+		if !naming.FluentSetters || (out != dstType && out != reflect.PointerTo(dstType)) {
+			return "", false
+		}
+	default:
+		return "", false
+	}
+
+	for _, prefix := range naming.SetterPrefixes {
+		name, ok := strings.CutPrefix(method.Name, prefix)
+		if !ok || prefix == "" {
+			continue
+		}
+		// SetName is a setter of Name, Settings is not a setter of tings
+		if r, _ := utf8.DecodeRuneInString(name); unicode.IsUpper(r) {
+			return name, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,416 @@
+package obj
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// structPlan describes how a source struct type is mapped to a destination struct
+// type. A map with string keys can be the source or destination instead of a
+// struct, in which case map keys are used instead of field names. Plans are
+// compiled on first use and cached by Mapper.
+type structPlan struct {
+	fields  []fieldPlan
+	setters []setterPlan
+
+	// whole is true if the source is assigned whole rather than field by field,
+	// being of the destination type with unexported fields, e.g. time.Time
+	whole bool
+
+	// unmapped lists the fields not mapped, nil if all are
+	unmapped *UnmappedFields
+}
+
+// fieldPlan describes how a field, or key of a destination map, is populated.
+type fieldPlan struct {
+	name      string
+	index     int
+	source    sourcePlan
+	omitEmpty bool
+	fieldMap  *FieldMapConfig
+
+	// nested is the plan of a struct field populated field by field, either
+	// because it is embedded or flattened in the source. nil if the field is
+	// populated from source.
+	nested *structPlan
+
+	// promoted indicates that the fields of nested are promoted from an embedded struct
+	promoted bool
+
+	// accessor writes the field when registered with RegisterFieldAccessor
+	accessor *fieldAccessor
+}
+
+// setterPlan describes how a setter of the destination struct is called.
+type setterPlan struct {
+	name      string
+	method    int
+	paramType reflect.Type
+	source    sourcePlan
+	fieldMap  *FieldMapConfig
+
+	// fluent indicates that the setter returns the destination, see NamingStrategy
+	fluent bool
+}
+
+// sourcePlan describes where the value of a source struct or map is read from.
+type sourcePlan struct {
+	// fieldIndex is the index of the source field, nil if not read from a field
+	fieldIndex []int
+
+	// mapKey is the key of the source map, invalid if the source is not a map
+	mapKey reflect.Value
+
+	// keyMatching matches other keys of the source map if mapKey is missing, nil
+	// if only mapKey matches
+	keyMatching NameMatching
+
+	// getter is the index of the getter of the source type, -1 if none
+	getter int
+
+	// pointerGetter indicates that getter is a method of the pointer to the source type
+	pointerGetter bool
+
+	// accessor reads the source field when registered with RegisterFieldAccessor
+	accessor *fieldAccessor
+}
+
+func (sp sourcePlan) found() bool {
+	return sp.fieldIndex != nil || sp.mapKey.IsValid() || sp.getter >= 0 || sp.accessor != nil
+}
+
+func (sp sourcePlan) value(src reflect.Value) reflect.Value {
+	if sp.accessor != nil {
+		return sp.accessor.get(src)
+	}
+	if sp.mapKey.IsValid() {
+		value := src.MapIndex(sp.mapKey)
+		if !value.IsValid() && sp.keyMatching != nil {
+			value = mapIndexMatching(src, sp.mapKey.String(), sp.keyMatching)
+		}
+		return value
+	}
+	if sp.fieldIndex != nil {
+		field, err := src.FieldByIndexErr(sp.fieldIndex)
+		if err != nil { // nil embedded pointer
+			return reflect.Value{}
+		}
+		return field
+	}
+	if sp.getter >= 0 && sp.pointerGetter {
+		if !src.CanAddr() {
+			addressable := reflect.New(src.Type()).Elem()
+			addressable.Set(src)
+			src = addressable
+		}
+		return src.Addr().Method(sp.getter).Call(nil)[0]
+	}
+	if sp.getter >= 0 {
+		return src.Method(sp.getter).Call(nil)[0]
+	}
+	return reflect.Value{}
+}
+
+// getterMethod returns the getter of srcType, the source must have one.
+func (sp sourcePlan) getterMethod(srcType reflect.Type) reflect.Method {
+	if sp.pointerGetter {
+		return reflect.PointerTo(srcType).Method(sp.getter)
+	}
+	return srcType.Method(sp.getter)
+}
+
+// valueType returns the type of the value read from srcType, nil if the source
+// is not found or is a map.
+func (sp sourcePlan) valueType(srcType reflect.Type) reflect.Type {
+	switch {
+	case sp.accessor != nil:
+		return sp.accessor.fieldType
+	case sp.fieldIndex != nil:
+		return srcType.FieldByIndex(sp.fieldIndex).Type
+	case sp.getter >= 0:
+		return sp.getterMethod(srcType).Type.Out(0)
+	}
+	return nil
+}
+
+func (m *Mapper) structPlan(srcType reflect.Type, dstType reflect.Type) *structPlan {
+	key := structMapKey{
+		source:      srcType,
+		destination: dstType,
+	}
+	if plan, ok := m.plans.Load(key); ok {
+		return plan.(*structPlan)
+	}
+	plan, _ := m.plans.LoadOrStore(key, m.compileStructPlan(srcType, dstType))
+	return plan.(*structPlan)
+}
+
+// resetPlans discards the cached plans after the configuration changed.
+func (m *Mapper) resetPlans() {
+	m.plans.Range(func(key, _ any) bool {
+		m.plans.Delete(key)
+		return true
+	})
+}
+
+func (m *Mapper) compileStructPlan(srcType reflect.Type, dstType reflect.Type) *structPlan {
+	fieldMaps := m.cfg.fieldMaps[structMapKey{
+		source:      srcType,
+		destination: dstType,
+	}]
+	if dstType.Kind() == reflect.Map {
+		return m.compileStructToMapPlan(srcType, fieldMaps)
+	}
+	if !m.cfg.clone && srcType == dstType && hasUnexportedFields(dstType) {
+		return &structPlan{whole: true}
+	}
+	plan := &structPlan{
+		fields: m.compileFields(srcType, dstType, fieldMaps, "", []reflect.Type{dstType}),
+	}
+
+	dstPtrType := reflect.PointerTo(dstType)
+	for i := 0; i < dstPtrType.NumMethod(); i++ {
+		method := dstPtrType.Method(i)
+		name, ok := m.setterName(dstType, method)
+		if !ok {
+			continue
+		}
+
+		setter := setterPlan{
+			name:      name,
+			method:    method.Index,
+			paramType: method.Type.In(1),
+			fieldMap:  fieldMaps[name],
+			fluent:    method.Type.NumOut() == 1,
+		}
+		srcName := setter.name
+		if setter.fieldMap != nil && len(setter.fieldMap.Source) > 0 {
+			srcName = setter.fieldMap.Source
+		}
+		if srcType.Kind() == reflect.Map {
+			setter.source = m.sourceByKey(srcType, srcName)
+		} else if setter.fieldMap != nil && len(setter.fieldMap.Source) > 0 {
+			setter.source = m.sourceByName(srcType, srcName)
+		} else {
+			setter.source, _ = m.sourceByTag(srcType, srcName)
+			if field, ok := m.exportedField(srcType, srcName); ok && parseFieldTag(field).skip {
+				continue
+			}
+		}
+		if !setter.source.found() {
+			setter.source.getter, setter.source.pointerGetter = m.getterIndex(srcType, srcName)
+		}
+		plan.setters = append(plan.setters, setter)
+	}
+	plan.unmapped = m.unmappedFields(srcType, dstType, plan)
+	return plan
+}
+
+// compileFields compiles the plans of the fields of dstType. Fields of embedded
+// structs not found in the source are compiled as promoted fields. With flattening,
+// fields of nested structs not found in the source are compiled with the name of
+// the nested field as prefix of their source names, e.g. Address.City from
+// AddressCity. parents holds the nested struct types being compiled to stop recursion.
+func (m *Mapper) compileFields(srcType reflect.Type, dstType reflect.Type, fieldMaps map[string]*FieldMapConfig,
+	prefix string, parents []reflect.Type) []fieldPlan {
+	var fields []fieldPlan
+	for i := 0; i < dstType.NumField(); i++ {
+		dstField := dstType.Field(i)
+		dstTag := parseFieldTag(dstField)
+		if dstTag.skip {
+			continue
+		}
+
+		field := fieldPlan{
+			name:      dstField.Name,
+			index:     i,
+			omitEmpty: dstTag.omitEmpty,
+			fieldMap:  fieldMaps[dstField.Name],
+			source:    sourcePlan{getter: -1},
+		}
+		if accessor := m.cfg.accessors[fieldKey{dstType, dstField.Name}]; accessor != nil && accessor.set != nil {
+			field.accessor = accessor
+		}
+		promotable := dstField.Anonymous && dstField.Type.Kind() == reflect.Struct
+		if !dstField.IsExported() && field.accessor == nil && !promotable {
+			continue // unexported fields can't be set
+		}
+
+		srcName := prefix + dstField.Name
+		switch {
+		case !dstField.IsExported() && field.accessor == nil:
+			// the exported fields of an unexported embedded struct are still promoted
+		case srcType.Kind() == reflect.Map:
+			if field.fieldMap != nil && len(field.fieldMap.Source) > 0 {
+				srcName = field.fieldMap.Source
+			} else if dstTag.name != "" {
+				srcName = prefix + dstTag.name
+			}
+			if !dstField.Anonymous || structType(dstField.Type) == nil {
+				field.source = m.sourceByKey(srcType, srcName)
+			}
+		case field.fieldMap != nil && len(field.fieldMap.Source) > 0:
+			srcName = field.fieldMap.Source
+			field.source = m.sourceByName(srcType, srcName)
+		case dstTag.name != "":
+			// the source field has the tag name or is tagged with it as well
+			srcName = prefix + dstTag.name
+			field.source, _ = m.sourceByTag(srcType, srcName)
+		default:
+			var srcTag fieldTag
+			field.source, srcTag = m.sourceByTag(srcType, srcName)
+			field.omitEmpty = field.omitEmpty || srcTag.omitEmpty
+		}
+		if accessor := m.cfg.accessors[fieldKey{srcType, srcName}]; accessor != nil && accessor.get != nil {
+			field.source = sourcePlan{getter: -1, accessor: accessor}
+		}
+		if !field.source.found() && (dstField.IsExported() || field.accessor != nil) {
+			field.source.getter, field.source.pointerGetter = m.getterIndex(srcType, srcName)
+		}
+
+		nestedType := structType(dstField.Type)
+		if !field.source.found() && field.accessor == nil && nestedType != nil && !slices.Contains(parents, nestedType) &&
+			(dstField.Anonymous || (m.cfg.flatten && srcType.Kind() == reflect.Struct)) {
+			nestedPrefix, nestedFieldMaps := prefix, fieldMaps
+			if !dstField.Anonymous {
+				nestedPrefix, nestedFieldMaps = srcName, nil
+			}
+			nested := m.compileFields(srcType, nestedType, nestedFieldMaps, nestedPrefix, append(parents, nestedType))
+			if hasSources(nested) {
+				field.nested = &structPlan{fields: nested}
+				field.promoted = dstField.Anonymous
+			}
+		}
+		if !field.source.found() && field.nested == nil && m.cfg.flatten && srcType.Kind() == reflect.Struct {
+			field.source = m.sourceByFlattenedName(srcType, srcName)
+		}
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+// hasSources returns true if any of the fields is populated from the source.
+func hasSources(fields []fieldPlan) bool {
+	for _, field := range fields {
+		if field.source.found() || field.nested != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// structType returns t, or the type t points to, if it is a struct. Returns nil otherwise.
+func structType(t reflect.Type) reflect.Type {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	return t
+}
+
+// compileStructToMapPlan compiles the plan of mapping the fields of srcType to the
+// keys of a map. The keys are the field names, unless renamed by a tag or fieldMaps.
+// Fields of embedded structs are mapped as if they were fields of srcType.
+func (m *Mapper) compileStructToMapPlan(srcType reflect.Type, fieldMaps map[string]*FieldMapConfig) *structPlan {
+	plan := &structPlan{}
+	keys := make(map[string]int)
+	for _, srcField := range reflect.VisibleFields(srcType) {
+		srcTag := parseFieldTag(srcField)
+		if !srcField.IsExported() || srcTag.skip {
+			continue
+		}
+		if srcField.Anonymous && srcTag.name == "" && structType(srcField.Type) != nil {
+			continue // the fields of embedded structs are promoted
+		}
+
+		key := srcField.Name
+		if srcTag.name != "" {
+			key = srcTag.name
+		}
+		keys[key] = len(plan.fields)
+		plan.fields = append(plan.fields, fieldPlan{
+			name:      key,
+			source:    sourcePlan{fieldIndex: srcField.Index, getter: -1},
+			omitEmpty: srcTag.omitEmpty,
+			fieldMap:  fieldMaps[key],
+		})
+	}
+
+	for key, fieldMap := range fieldMaps {
+		if len(fieldMap.Source) == 0 {
+			continue
+		}
+		field := fieldPlan{
+			name:     key,
+			source:   m.sourceByName(srcType, fieldMap.Source),
+			fieldMap: fieldMap,
+		}
+		if i, ok := keys[key]; ok {
+			plan.fields[i] = field
+		} else {
+			plan.fields = append(plan.fields, field)
+		}
+	}
+	return plan
+}
+
+func (m *Mapper) sourceByKey(srcType reflect.Type, key string) sourcePlan {
+	return sourcePlan{
+		mapKey:      reflect.ValueOf(key).Convert(srcType.Key()),
+		keyMatching: m.cfg.nameMatching,
+		getter:      -1,
+	}
+}
+
+func (m *Mapper) sourceByName(srcType reflect.Type, name string) sourcePlan {
+	sp := sourcePlan{getter: -1}
+	if field, ok := m.exportedField(srcType, name); ok {
+		sp.fieldIndex = field.Index
+	}
+	return sp
+}
+
+func (m *Mapper) sourceByTag(srcType reflect.Type, dstName string) (sourcePlan, fieldTag) {
+	sp := sourcePlan{getter: -1}
+	field, tag, ok := m.sourceFieldByTag(srcType, dstName)
+	if ok {
+		sp.fieldIndex = field.Index
+	}
+	return sp, tag
+}
+
+// sourceByFlattenedName returns the source of a name flattened from a nested
+// struct, e.g. the field Address.City for AddressCity.
+func (m *Mapper) sourceByFlattenedName(srcType reflect.Type, name string) sourcePlan {
+	return sourcePlan{
+		fieldIndex: m.flattenedFieldIndex(srcType, m.normalizeName(name), []reflect.Type{srcType}),
+		getter:     -1,
+	}
+}
+
+// flattenedFieldIndex returns the index of the nested field of srcType flattened
+// to name, which is normalized by the name matching of the Mapper.
+func (m *Mapper) flattenedFieldIndex(srcType reflect.Type, name string, parents []reflect.Type) []int {
+	for _, field := range reflect.VisibleFields(srcType) {
+		fieldName := m.normalizeName(field.Name)
+		if !field.IsExported() || len(fieldName) >= len(name) || !strings.HasPrefix(name, fieldName) {
+			continue
+		}
+		nestedType := structType(field.Type)
+		if nestedType == nil || slices.Contains(parents, nestedType) {
+			continue
+		}
+
+		rest := name[len(fieldName):]
+		if nestedField, ok := m.exportedField(nestedType, rest); ok {
+			return append(slices.Clone(field.Index), nestedField.Index...)
+		}
+		if index := m.flattenedFieldIndex(nestedType, rest, append(parents, nestedType)); index != nil {
+			return append(slices.Clone(field.Index), index...)
+		}
+	}
+	return nil
+}
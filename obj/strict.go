@@ -0,0 +1,233 @@
+package obj
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// ErrUnmappedField returned in strict mode when fields are not mapped, see
+// WithStrictMode. The returned error is an *UnmappedFieldsError.
+var ErrUnmappedField error = fmt.Errorf("unmapped field")
+
+// UnmappedFields lists the fields of a source and destination type pair which are
+// not mapped. Fields of nested structs populated field by field are named after
+// their path, e.g. Address.City.
+type UnmappedFields struct {
+	// Destination lists the destination fields with no source
+	Destination []string
+
+	// Source lists the source fields not mapped to any destination field
+	Source []string
+}
+
+// UnmappedFieldsError is returned in strict mode and by Validate when fields of a
+// type pair are not mapped.
+type UnmappedFieldsError struct {
+	UnmappedFields
+
+	// SourceType is the type of the source struct
+	SourceType reflect.Type
+
+	// DestinationType is the type of the destination struct
+	DestinationType reflect.Type
+}
+
+func (e *UnmappedFieldsError) Error() string {
+	var parts []string
+	if len(e.Destination) > 0 {
+		parts = append(parts, fmt.Sprintf("destination fields of %s with no source: %s",
+			e.DestinationType, strings.Join(e.Destination, ", ")))
+	}
+	if len(e.Source) > 0 {
+		parts = append(parts, fmt.Sprintf("unused source fields of %s: %s",
+			e.SourceType, strings.Join(e.Source, ", ")))
+	}
+	return strings.Join(parts, "; ")
+}
+
+func (e *UnmappedFieldsError) Unwrap() error {
+	return ErrUnmappedField
+}
+
+// AllowUnmappedFields excludes fields of sourceT and destinationT from the fields
+// reported in strict mode and by Validate. Calling it again for the same types
+// adds to the allowed fields.
+func AllowUnmappedFields[sourceT any, destinationT any](mapper *Mapper, allowed UnmappedFields) error {
+	key := structMapKey{
+		source:      reflect.TypeFor[sourceT](),
+		destination: reflect.TypeFor[destinationT](),
+	}
+	if key.source.Kind() != reflect.Struct || key.destination.Kind() != reflect.Struct {
+		return fmt.Errorf("sourceT and destinationT must be structs")
+	}
+
+	if mapper.cfg.allowedUnmapped == nil {
+		mapper.cfg.allowedUnmapped = make(map[structMapKey]UnmappedFields)
+	}
+	allowedFields := mapper.cfg.allowedUnmapped[key]
+	allowedFields.Destination = append(allowedFields.Destination, allowed.Destination...)
+	allowedFields.Source = append(allowedFields.Source, allowed.Source...)
+	mapper.cfg.allowedUnmapped[key] = allowedFields
+	mapper.resetPlans()
+	return nil
+}
+
+// Validate checks that every field of destinationT has a source and that every
+// field of sourceT is mapped, including the struct types of their fields, elements
+// of slices, arrays and maps, and pointers. Fields allowed with AllowUnmappedFields
+// are ignored. The returned error joins an *UnmappedFieldsError, wrapped in a
+// *MappingError with the path of the field, for each type pair with unmapped
+// fields. Validate is meant to be called from unit tests to catch fields renamed
+// in one struct but not the other.
+// Sample usage:
+//
+//	func TestUserMapping(t *testing.T) {
+//		if err := obj.Validate[UserEntity, UserDTO](mapper); err != nil {
+//			t.Error(err)
+//		}
+//	}
+func Validate[sourceT any, destinationT any](mapper *Mapper) error {
+	state := &mapState{collectErrors: true}
+	mapper.validateTypes(state, reflect.TypeFor[sourceT](), reflect.TypeFor[destinationT](), make(map[structMapKey]bool))
+	return errors.Join(state.errs...)
+}
+
+// validateTypes validates the struct types of srcType and dstType, or of their
+// elements if they are pointers, slices, arrays or maps.
+func (m *Mapper) validateTypes(state *mapState, srcType reflect.Type, dstType reflect.Type, visited map[structMapKey]bool) {
+	for {
+		if _, ok := m.cfg.converters[structMapKey{source: srcType, destination: dstType}]; ok {
+			return
+		}
+		switch {
+		case srcType.Kind() == reflect.Pointer:
+			srcType = srcType.Elem()
+		case dstType.Kind() == reflect.Pointer:
+			dstType = dstType.Elem()
+		case (srcType.Kind() == reflect.Slice || srcType.Kind() == reflect.Array) &&
+			(dstType.Kind() == reflect.Slice || dstType.Kind() == reflect.Array):
+			srcType, dstType = srcType.Elem(), dstType.Elem()
+		case srcType.Kind() == reflect.Map && dstType.Kind() == reflect.Map:
+			srcType, dstType = srcType.Elem(), dstType.Elem()
+		case srcType.Kind() == reflect.Struct && dstType.Kind() == reflect.Struct:
+			m.validateStructs(state, srcType, dstType, visited)
+			return
+		default:
+			return
+		}
+	}
+}
+
+func (m *Mapper) validateStructs(state *mapState, srcType reflect.Type, dstType reflect.Type, visited map[structMapKey]bool) {
+	key := structMapKey{source: srcType, destination: dstType}
+	if visited[key] {
+		return
+	}
+	visited[key] = true
+
+	plan := m.structPlan(srcType, dstType)
+	if plan.unmapped != nil {
+		_ = state.fail(&UnmappedFieldsError{
+			UnmappedFields:  *plan.unmapped,
+			SourceType:      srcType,
+			DestinationType: dstType,
+		}, srcType, dstType)
+	}
+	m.validateFields(state, plan.fields, srcType, dstType, visited)
+	for _, setter := range plan.setters {
+		if srcFieldType := setter.source.valueType(srcType); srcFieldType != nil && setter.fieldMap == nil {
+			state.pushField(setter.name)
+			m.validateTypes(state, srcFieldType, setter.paramType, visited)
+			state.pop()
+		}
+	}
+}
+
+func (m *Mapper) validateFields(state *mapState, fields []fieldPlan, srcType reflect.Type, dstType reflect.Type,
+	visited map[structMapKey]bool) {
+	for _, field := range fields {
+		dstFieldType := dstType.Field(field.index).Type
+		if field.nested != nil {
+			if !field.promoted {
+				state.pushField(field.name)
+			}
+			m.validateFields(state, field.nested.fields, srcType, structType(dstFieldType), visited)
+			if !field.promoted {
+				state.pop()
+			}
+			continue
+		}
+
+		srcFieldType := field.source.valueType(srcType)
+		if srcFieldType == nil || (field.fieldMap != nil && field.fieldMap.GetDestinationValue != nil) {
+			continue
+		}
+		state.pushField(field.name)
+		m.validateTypes(state, srcFieldType, dstFieldType, visited)
+		state.pop()
+	}
+}
+
+// unmappedFields returns the fields of srcType and dstType which are not mapped by
+// plan and not allowed to be, nil if there are none. Only struct pairs are checked
+// as the keys of maps are not known in advance.
+func (m *Mapper) unmappedFields(srcType reflect.Type, dstType reflect.Type, plan *structPlan) *UnmappedFields {
+	if srcType.Kind() != reflect.Struct || dstType.Kind() != reflect.Struct {
+		return nil
+	}
+	allowed := m.cfg.allowedUnmapped[structMapKey{source: srcType, destination: dstType}]
+
+	unmapped := &UnmappedFields{}
+	var used [][]int
+	var walk func(fields []fieldPlan, prefix string)
+	walk = func(fields []fieldPlan, prefix string) {
+		for _, field := range fields {
+			name := prefix + field.name
+			switch {
+			case field.nested != nil && field.promoted:
+				walk(field.nested.fields, prefix)
+			case field.nested != nil:
+				walk(field.nested.fields, name+".")
+			case field.source.found():
+				used = append(used, field.source.fieldIndex)
+			case field.fieldMap == nil || field.fieldMap.GetDestinationValue == nil:
+				if !slices.Contains(allowed.Destination, name) {
+					unmapped.Destination = append(unmapped.Destination, name)
+				}
+			}
+		}
+	}
+	walk(plan.fields, "")
+	for _, setter := range plan.setters {
+		used = append(used, setter.source.fieldIndex)
+	}
+
+	for _, srcField := range reflect.VisibleFields(srcType) {
+		if !srcField.IsExported() || parseFieldTag(srcField).skip ||
+			(srcField.Anonymous && structType(srcField.Type) != nil) ||
+			isUsedField(srcField.Index, used) || slices.Contains(allowed.Source, srcField.Name) {
+			continue
+		}
+		unmapped.Source = append(unmapped.Source, srcField.Name)
+	}
+
+	if len(unmapped.Destination) == 0 && len(unmapped.Source) == 0 {
+		return nil
+	}
+	return unmapped
+}
+
+// isUsedField returns true if the field at index, a field it is embedded in or a
+// field embedded or nested in it is read.
+func isUsedField(index []int, used [][]int) bool {
+	for _, usedIndex := range used {
+		n := min(len(index), len(usedIndex))
+		if n > 0 && slices.Equal(index[:n], usedIndex[:n]) {
+			return true
+		}
+	}
+	return false
+}
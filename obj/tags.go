@@ -0,0 +1,80 @@
+package obj
+
+import (
+	"reflect"
+	"strings"
+)
+
+// tagKey is the struct tag key read by Mapper. Supported tags:
+//
+//	Name     string `map:"FullName"`   // mapped from/to the field FullName of the other struct
+//	Secret   string `map:"-"`          // never mapped
+//	Nickname string `map:",omitempty"` // not mapped when the source value is zero
+const tagKey = "map"
+
+type fieldTag struct {
+	name      string
+	skip      bool
+	omitEmpty bool
+}
+
+func parseFieldTag(field reflect.StructField) fieldTag {
+	tag, ok := field.Tag.Lookup(tagKey)
+	if !ok {
+		return fieldTag{}
+	}
+	if tag == "-" {
+		return fieldTag{skip: true}
+	}
+
+	name, options, _ := strings.Cut(tag, ",")
+	parsed := fieldTag{name: name}
+	for _, option := range strings.Split(options, ",") {
+		if option == "omitempty" {
+			parsed.omitEmpty = true
+		}
+	}
+	return parsed
+}
+
+// sourceFieldByTag returns the field of the source struct to be mapped to the
+// destination field named dstName, honoring the map tags of the source fields.
+// Names matching exactly are preferred over names matched by the name matching of
+// the Mapper. Unexported fields are never returned.
+func (m *Mapper) sourceFieldByTag(srcType reflect.Type, dstName string) (reflect.StructField, fieldTag, bool) {
+	field, tag, ok := sourceFieldByTag(srcType, dstName, func(name string) bool {
+		return name == dstName
+	})
+	if ok || m.cfg.nameMatching == nil {
+		return field, tag, ok
+	}
+
+	normalized := m.cfg.nameMatching(dstName)
+	return sourceFieldByTag(srcType, dstName, func(name string) bool {
+		return m.cfg.nameMatching(name) == normalized
+	})
+}
+
+// sourceFieldByTag returns the source field tagged with or named as a name matching
+// dstName, preferring tags over names.
+func sourceFieldByTag(srcType reflect.Type, dstName string, match func(name string) bool) (reflect.StructField,
+	fieldTag, bool) {
+	fields := reflect.VisibleFields(srcType)
+	for _, field := range fields {
+		if tag := parseFieldTag(field); tag.name != "" && match(tag.name) && field.IsExported() {
+			return field, tag, true
+		}
+	}
+
+	for _, field := range fields {
+		if !field.IsExported() || !match(field.Name) {
+			continue
+		}
+		tag := parseFieldTag(field)
+		if tag.skip || (tag.name != "" && !match(tag.name)) {
+			continue
+		}
+		return field, tag, true
+	}
+	return reflect.StructField{}, fieldTag{}, false
+}